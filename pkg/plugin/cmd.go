@@ -3,6 +3,7 @@ package plugin
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/emirozer/kubectl-doctor/pkg/client"
@@ -13,6 +14,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
@@ -29,7 +31,7 @@ const (
     example anomalies: 
         * deployments that are older than 30d with 0 available, 
         * deployments that do not have minimum availability,
-        * kubernetes nodes cpu usage or memory usage too high. or too low to report scaledown possiblity 
+        * kubernetes nodes cpu usage or memory usage too high. or too low to report scaledown possiblity (requires metrics-server)
 `
 
 	usageError = "expects no flags .. 'doctor' for doctor command"
@@ -52,16 +54,37 @@ func init() {
 // DoctorOptions specify what the doctor is going to do
 type DoctorOptions struct {
 	FetchedNamespaces []string
+	// labels of the fetched namespaces by name
+	NamespaceLabels map[string]map[string]string
 
 	// Doctor options
-	DeploymentOnly bool
-	FullScan       bool
-	Flags          *genericclioptions.ConfigFlags
-	CoreClient     coreclient.CoreV1Interface
-	RESTClient     *restclient.RESTClient
-	KubeCli        *kubernetes.Clientset
-	Args           []string
-	Config         *restclient.Config
+	DeploymentOnly       bool
+	FullScan             bool
+	CordonThreshold      time.Duration
+	NodeRequestThreshold int64
+	NodeUsageHigh        int64
+	NodeUsageLow         int64
+	LimitRequestRatio    float64
+	ProductionSelector   string
+	AllowedRegistries    []string
+	MaxImageVersions     int
+	HPAPinnedThreshold   time.Duration
+	QuotaThreshold       int64
+	TerminatingThreshold time.Duration
+	DeprecatedAPIWindow  int
+	LeftoverThreshold    time.Duration
+	PVCPendingThreshold  time.Duration
+	AttachThreshold      time.Duration
+	JobThreshold         time.Duration
+	EventsLookback       time.Duration
+	EventsTop            int
+	Flags                *genericclioptions.ConfigFlags
+	CoreClient           coreclient.CoreV1Interface
+	RESTClient           *restclient.RESTClient
+	KubeCli              *kubernetes.Clientset
+	DynamicCli           dynamic.Interface
+	Args                 []string
+	Config               *restclient.Config
 }
 
 // NewDoctorOptions new doctor options initializer
@@ -90,6 +113,42 @@ func NewDoctorCmd() *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&opts.DeploymentOnly, "deployment-only", false,
 		"Only triage deployments in a given namespace")
+	cmd.Flags().DurationVar(&opts.CordonThreshold, "cordon-threshold", 24*time.Hour,
+		"Report nodes that have been cordoned for longer than this duration")
+	cmd.Flags().Int64Var(&opts.NodeRequestThreshold, "node-request-threshold", 100,
+		"Report nodes whose pods request more than this percentage of allocatable cpu/memory")
+	cmd.Flags().Int64Var(&opts.NodeUsageHigh, "node-usage-high", 80,
+		"Report nodes using more than this percentage of allocatable cpu/memory (requires metrics-server)")
+	cmd.Flags().Int64Var(&opts.NodeUsageLow, "node-usage-low", 20,
+		"Report nodes using less than this percentage of allocatable cpu and memory as scale down candidates (requires metrics-server)")
+	cmd.Flags().Float64Var(&opts.LimitRequestRatio, "limit-request-ratio", 4,
+		"Report containers whose cpu/memory limit is more than this many times their request")
+	cmd.Flags().StringVar(&opts.ProductionSelector, "production-namespace-selector", "environment=production",
+		"Label selector of the namespaces where BestEffort QoS workloads are reported")
+	cmd.Flags().StringSliceVar(&opts.AllowedRegistries, "allowed-registries", nil,
+		"Report images pulled from registries not in this list, the check is skipped when empty")
+	cmd.Flags().IntVar(&opts.MaxImageVersions, "max-image-versions", 3,
+		"Report images running with more than this many different tags across the cluster")
+	cmd.Flags().DurationVar(&opts.HPAPinnedThreshold, "hpa-pinned-threshold", 6*time.Hour,
+		"Report HPAs that have been pinned at maxReplicas for longer than this duration")
+	cmd.Flags().Int64Var(&opts.QuotaThreshold, "quota-threshold", 90,
+		"Report resource quotas whose usage of any resource is above this percentage")
+	cmd.Flags().DurationVar(&opts.TerminatingThreshold, "terminating-threshold", time.Hour,
+		"Report objects that have been terminating for longer than this duration")
+	cmd.Flags().IntVar(&opts.DeprecatedAPIWindow, "deprecated-api-window", 2,
+		"Report API versions removed within this many minor releases after the server's version")
+	cmd.Flags().DurationVar(&opts.LeftoverThreshold, "leftover-threshold", 7*24*time.Hour,
+		"Report unreferenced configmaps and secrets, and bound pvcs not mounted by a running pod, older than this duration")
+	cmd.Flags().DurationVar(&opts.PVCPendingThreshold, "pvc-pending-threshold", 15*time.Minute,
+		"Report pvcs that have been Pending for longer than this duration")
+	cmd.Flags().DurationVar(&opts.AttachThreshold, "attach-threshold", 5*time.Minute,
+		"Report volume attachments that are still not attached after this duration")
+	cmd.Flags().DurationVar(&opts.JobThreshold, "job-threshold", 24*time.Hour,
+		"Report jobs running or finished without ttlSecondsAfterFinished for longer than this duration")
+	cmd.Flags().DurationVar(&opts.EventsLookback, "events-lookback", time.Hour,
+		"Only consider warning events last seen within this duration")
+	cmd.Flags().IntVar(&opts.EventsTop, "events-top", 10,
+		"Number of objects emitting the most warning events to report")
 
 	opts.Flags.AddFlags(cmd.Flags())
 
@@ -121,8 +180,10 @@ func (o *DoctorOptions) Complete(cmd *cobra.Command, args []string, argsLenAtDas
 	o.CoreClient = clientset.CoreV1()
 
 	fetchedNamespaces, _ := o.CoreClient.Namespaces().List(v1.ListOptions{})
+	o.NamespaceLabels = make(map[string]map[string]string)
 	for _, i := range fetchedNamespaces.Items {
 		o.FetchedNamespaces = append(o.FetchedNamespaces, i.GetName())
+		o.NamespaceLabels[i.GetName()] = i.GetLabels()
 	}
 	log.Info("")
 	log.Info("Fetched namespaces: ", o.FetchedNamespaces)
@@ -133,6 +194,11 @@ func (o *DoctorOptions) Complete(cmd *cobra.Command, args []string, argsLenAtDas
 		return err
 	}
 
+	o.DynamicCli, err = dynamic.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -174,6 +240,14 @@ func (o *DoctorOptions) Run() error {
 	if len(componentsTriage.Anomalies) > 0 {
 		report["TriageReport"] = append(report["TriageReport"], componentsTriage)
 	}
+
+	apiServicesTriage, err := triage.TriageAPIServices(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(apiServicesTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], apiServicesTriage)
+	}
 	// triage cluster crucial components ends
 
 	// triage nodes stars
@@ -185,8 +259,95 @@ func (o *DoctorOptions) Run() error {
 	if len(nodesTriage.Anomalies) > 0 {
 		report["TriageReport"] = append(report["TriageReport"], nodesTriage)
 	}
+
+	nodeConditionsTriage, err := triage.TriageNodeConditions(o.CoreClient)
+	if err != nil {
+		return err
+	}
+	if len(nodeConditionsTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], nodeConditionsTriage)
+	}
+
+	cordonedNodesTriage, err := triage.TriageCordonedNodes(o.CoreClient, o.CordonThreshold)
+	if err != nil {
+		return err
+	}
+	if len(cordonedNodesTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], cordonedNodesTriage)
+	}
+
+	taintedNodesTriage, err := triage.TriageNoExecuteTaints(o.CoreClient)
+	if err != nil {
+		return err
+	}
+	if len(taintedNodesTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], taintedNodesTriage)
+	}
+
+	overcommitTriage, err := triage.TriageNodeOvercommit(o.CoreClient, o.NodeRequestThreshold)
+	if err != nil {
+		return err
+	}
+	if len(overcommitTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], overcommitTriage)
+	}
+
+	nodeMetrics := triage.FetchNodeMetrics(o.KubeCli)
+	nodeUsageTriage, err := triage.TriageNodeUsage(o.KubeCli, nodeMetrics, o.NodeUsageHigh)
+	if err != nil {
+		return err
+	}
+	if len(nodeUsageTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], nodeUsageTriage)
+	}
+
+	nodeScaleDownTriage, err := triage.TriageNodeScaleDown(nodeMetrics, o.NodeUsageLow)
+	if err != nil {
+		return err
+	}
+	if len(nodeScaleDownTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], nodeScaleDownTriage)
+	}
 	// triage nodes ends
 
+	// triage admission webhooks starts
+	log.Info("Starting triage of admission webhooks.")
+	webhooksTriage, err := triage.TriageWebhooks(o.KubeCli, o.DynamicCli)
+	if err != nil {
+		return err
+	}
+	if len(webhooksTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], webhooksTriage)
+	}
+	// triage admission webhooks ends
+
+	// triage rbac starts
+	log.Info("Starting triage of RBAC bindings.")
+	rbacPrivilegesTriage, err := triage.TriageRBACPrivileges(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(rbacPrivilegesTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], rbacPrivilegesTriage)
+	}
+
+	danglingBindingsTriage, err := triage.TriageRBACDanglingBindings(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(danglingBindingsTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], danglingBindingsTriage)
+	}
+
+	defaultSATriage, err := triage.TriageDefaultServiceAccountBindings(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(defaultSATriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], defaultSATriage)
+	}
+	// triage rbac ends
+
 	// triage endpoints starts
 	log.Info("Starting triage of cluster-wide Endpoints resources.")
 
@@ -208,6 +369,30 @@ func (o *DoctorOptions) Run() error {
 	if len(pvcTriage.Anomalies) > 0 {
 		report["TriageReport"] = append(report["TriageReport"], pvcTriage)
 	}
+
+	pendingPVCTriage, err := triage.TriagePendingPVC(o.KubeCli, o.PVCPendingThreshold)
+	if err != nil {
+		return err
+	}
+	if len(pendingPVCTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], pendingPVCTriage)
+	}
+
+	pvcStorageClassTriage, err := triage.TriagePVCStorageClass(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(pvcStorageClassTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], pvcStorageClassTriage)
+	}
+
+	unusedPVCTriage, err := triage.TriageUnusedPVC(o.CoreClient, o.LeftoverThreshold)
+	if err != nil {
+		return err
+	}
+	if len(unusedPVCTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], unusedPVCTriage)
+	}
 	// triage pvc ends
 
 	// triage pv starts
@@ -219,8 +404,59 @@ func (o *DoctorOptions) Run() error {
 	if len(pvTriage.Anomalies) > 0 {
 		report["TriageReport"] = append(report["TriageReport"], pvTriage)
 	}
+
+	releasedPVTriage, err := triage.TriageReleasedPV(o.CoreClient)
+	if err != nil {
+		return err
+	}
+	if len(releasedPVTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], releasedPVTriage)
+	}
+
+	statefulPVTriage, err := triage.TriageStatefulPVReclaimPolicy(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(statefulPVTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], statefulPVTriage)
+	}
 	// triage pv ends
 
+	// triage storage starts
+	log.Info("Starting triage of storage classes and csi drivers.")
+	defaultStorageClassTriage, err := triage.TriageDefaultStorageClass(o.KubeCli)
+	if err != nil {
+		return err
+	}
+	if len(defaultStorageClassTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], defaultStorageClassTriage)
+	}
+
+	csiDriversTriage, err := triage.TriageCSIDrivers(o.KubeCli, o.DynamicCli)
+	if err != nil {
+		return err
+	}
+	if len(csiDriversTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], csiDriversTriage)
+	}
+
+	volumeAttachmentsTriage, err := triage.TriageVolumeAttachments(o.KubeCli, o.AttachThreshold)
+	if err != nil {
+		return err
+	}
+	if len(volumeAttachmentsTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], volumeAttachmentsTriage)
+	}
+
+	csiNodesTriage, err := triage.TriageCSINodes(o.KubeCli, o.DynamicCli)
+	if err != nil {
+		return err
+	}
+	if len(csiNodesTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], csiNodesTriage)
+	}
+	// triage storage ends
+
 	// triage deployments starts
 	log.Info("Starting triage of deployment resources across cluster")
 	for _, ns := range o.FetchedNamespaces {
@@ -266,16 +502,32 @@ func (o *DoctorOptions) Run() error {
 	// triage replicasets ends
 
 	// triage jobs starts
-	log.Info("Starting triage of cronjob resources across cluster")
+	log.Info("Starting triage of job and cronjob resources across cluster")
 	var jobsTriage *triage.Triage
 	for _, ns := range o.FetchedNamespaces {
-		jobsTriage, err = triage.LeftoverJobs(o.KubeCli, ns)
+		jobsTriage, err = triage.LeftoverJobs(o.DynamicCli, ns)
 		if err != nil {
 			return err
 		}
 		if len(jobsTriage.Anomalies) > 0 {
 			report["TriageReport"] = append(report["TriageReport"], jobsTriage)
 		}
+
+		batchJobsTriage, err := triage.TriageJobs(o.KubeCli, ns, o.JobThreshold)
+		if err != nil {
+			return err
+		}
+		if len(batchJobsTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], batchJobsTriage)
+		}
+
+		cronJobsTriage, err := triage.TriageCronJobs(o.KubeCli, o.DynamicCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(cronJobsTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], cronJobsTriage)
+		}
 	}
 	// triage jobs end
 
@@ -293,6 +545,221 @@ func (o *DoctorOptions) Run() error {
 	}
 	// triage ingresses ends
 
+	// triage configmaps and secrets starts
+	log.Info("Starting triage of configmap and secret resources across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		configMapsTriage, err := triage.LeftOverConfigMaps(o.KubeCli, o.DynamicCli, ns, o.LeftoverThreshold)
+		if err != nil {
+			return err
+		}
+		if len(configMapsTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], configMapsTriage)
+		}
+
+		secretsTriage, err := triage.LeftOverSecrets(o.KubeCli, o.DynamicCli, ns, o.LeftoverThreshold)
+		if err != nil {
+			return err
+		}
+		if len(secretsTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], secretsTriage)
+		}
+	}
+	// triage configmaps and secrets ends
+
+	// triage broken references starts
+	log.Info("Starting triage of references to missing objects across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		referencesTriage, err := triage.TriageBrokenReferences(o.KubeCli, o.DynamicCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(referencesTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], referencesTriage)
+		}
+	}
+	// triage broken references ends
+
+	// triage workload resources starts
+	log.Info("Starting triage of workload resource requests/limits across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		resourcesTriage, err := triage.TriageResourceHygiene(o.KubeCli, ns, o.LimitRequestRatio)
+		if err != nil {
+			return err
+		}
+		if len(resourcesTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], resourcesTriage)
+		}
+	}
+
+	productionNamespaces, err := o.CoreClient.Namespaces().List(v1.ListOptions{LabelSelector: o.ProductionSelector})
+	if err != nil {
+		return err
+	}
+	for _, ns := range productionNamespaces.Items {
+		bestEffortTriage, err := triage.TriageBestEffortWorkloads(o.KubeCli, ns.GetName())
+		if err != nil {
+			return err
+		}
+		if len(bestEffortTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], bestEffortTriage)
+		}
+	}
+	// triage workload resources ends
+
+	// triage pod security starts
+	log.Info("Starting triage of workload pod security across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		podSecurityTriage, err := triage.TriagePodSecurity(o.KubeCli, o.DynamicCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(podSecurityTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], podSecurityTriage)
+		}
+	}
+	// triage pod security ends
+
+	// triage network policies starts
+	log.Info("Starting triage of network policy coverage across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		networkPoliciesTriage, err := triage.TriageNetworkPolicies(o.KubeCli, ns, o.NamespaceLabels)
+		if err != nil {
+			return err
+		}
+		if len(networkPoliciesTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], networkPoliciesTriage)
+		}
+	}
+	// triage network policies ends
+
+	// triage probes starts
+	log.Info("Starting triage of workload liveness/readiness probes across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		probesTriage, err := triage.TriageProbes(o.KubeCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(probesTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], probesTriage)
+		}
+	}
+	// triage probes ends
+
+	// triage images starts
+	log.Info("Starting triage of container images across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		imageTagsTriage, err := triage.TriageImageTags(o.KubeCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(imageTagsTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], imageTagsTriage)
+		}
+
+		if len(o.AllowedRegistries) > 0 {
+			registriesTriage, err := triage.TriageImageRegistries(o.KubeCli, ns, o.AllowedRegistries)
+			if err != nil {
+				return err
+			}
+			if len(registriesTriage.Anomalies) > 0 {
+				report["TriageReport"] = append(report["TriageReport"], registriesTriage)
+			}
+		}
+	}
+
+	imageDriftTriage, err := triage.TriageImageDrift(o.KubeCli, o.MaxImageVersions)
+	if err != nil {
+		return err
+	}
+	if len(imageDriftTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], imageDriftTriage)
+	}
+	// triage images ends
+
+	// triage hpa starts
+	log.Info("Starting triage of horizontal pod autoscalers across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		hpaTriage, err := triage.TriageHPA(o.KubeCli, o.DynamicCli, ns, o.HPAPinnedThreshold)
+		if err != nil {
+			return err
+		}
+		if len(hpaTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], hpaTriage)
+		}
+	}
+	// triage hpa ends
+
+	// triage pdb starts
+	log.Info("Starting triage of pod disruption budgets across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		pdbTriage, err := triage.TriagePDB(o.KubeCli, o.DynamicCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(pdbTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], pdbTriage)
+		}
+	}
+	// triage pdb ends
+
+	// triage quotas starts
+	log.Info("Starting triage of resource quotas and limit ranges across cluster")
+	for _, ns := range o.FetchedNamespaces {
+		quotaTriage, err := triage.TriageResourceQuotas(o.KubeCli, ns, o.QuotaThreshold)
+		if err != nil {
+			return err
+		}
+		if len(quotaTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], quotaTriage)
+		}
+
+		limitRangeTriage, err := triage.TriageLimitRanges(o.KubeCli, ns)
+		if err != nil {
+			return err
+		}
+		if len(limitRangeTriage.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], limitRangeTriage)
+		}
+	}
+	// triage quotas ends
+
+	// triage cluster objects starts
+	log.Info("Starting triage of terminating objects, owner references and deprecated API versions across cluster")
+	objectWalk, err := triage.WalkObjects(o.KubeCli, o.DynamicCli, o.TerminatingThreshold, o.DeprecatedAPIWindow)
+	if err != nil {
+		return err
+	}
+	for _, t := range objectWalk.Triages {
+		if len(t.Anomalies) > 0 {
+			report["TriageReport"] = append(report["TriageReport"], t)
+		}
+	}
+	// triage cluster objects ends
+
+	// root cause correlation starts
+	log.Info("Correlating findings through owner references and service selectors")
+	report["TriageReport"], err = triage.CorrelateFindings(o.KubeCli, objectWalk, report["TriageReport"])
+	if err != nil {
+		return err
+	}
+	// root cause correlation ends
+
+	// triage warning events starts
+	log.Info("Starting triage of warning events across cluster")
+	warningEvents, err := triage.CollectWarningEvents(o.KubeCli, o.EventsLookback)
+	if err != nil {
+		return err
+	}
+	triage.AttachEvidence(objectWalk, report["TriageReport"], warningEvents)
+	warningEventsTriage, err := triage.TriageWarningEvents(warningEvents, o.EventsTop)
+	if err != nil {
+		return err
+	}
+	if len(warningEventsTriage.Anomalies) > 0 {
+		report["TriageReport"] = append(report["TriageReport"], warningEventsTriage)
+	}
+	// triage warning events ends
+
 	// yaml outputter
 	if len(report["TriageReport"]) > 0 {
 		log.Info("Triage report coming up in yaml format:")
@@ -1,29 +1,181 @@
 package triage
 
 import (
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
-const componentHealthy = "True"
+const (
+	componentHealthy    = "True"
+	apiServiceAvailable = "Available"
+)
+
+// control plane components that run as static pods in kube-system on self-hosted clusters, by their component label
+var controlPlaneComponents = []string{"etcd", "kube-apiserver", "kube-scheduler", "kube-controller-manager"}
 
 // TriageComponents gets a coreclient and checks if core components are in healthy state
-// such as etcd cluster members, scheduler, controller-manager
+// such as etcd cluster members, scheduler, controller-manager.
+// Health comes from the verbose /livez and /readyz endpoints of the API server and from the control plane
+// static pods in kube-system when they are visible, the deprecated ComponentStatuses API is only used
+// on servers that do not serve the health endpoints
 func TriageComponents(coreClient coreclient.CoreV1Interface) (*Triage, error) {
-	components, err := coreClient.ComponentStatuses().List(v1.ListOptions{})
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+
+	served := false
+	for _, endpoint := range []string{"/livez", "/readyz"} {
+		failedChecks, ok := failedHealthChecks(coreClient, endpoint)
+		if !ok {
+			continue
+		}
+		served = true
+		for _, check := range failedChecks {
+			listOfTriages = append(listOfTriages, endpoint+": "+check)
+		}
+	}
+
+	if !served {
+		components, err := coreClient.ComponentStatuses().List(v1.ListOptions{})
+		if err != nil {
+			if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+				return nil, err
+			}
+		}
+		for _, i := range components.Items {
+			for _, y := range i.Conditions {
+				if y.Status != componentHealthy {
+					listOfTriages = append(listOfTriages, i.GetName())
+				}
+			}
+		}
+	}
+
+	pods, err := coreClient.Pods(kubeSystemNamespace).List(v1.ListOptions{
+		LabelSelector: "component in (" + strings.Join(controlPlaneComponents, ",") + ")",
+	})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
 			return nil, err
 		}
 	}
+	for _, i := range pods.Items {
+		if !podReady(&i) {
+			anomaly := kubeSystemNamespace + "/" + i.GetName() + ": " + podNotReadyReason(&i)
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Pod", kubeSystemNamespace, i.GetName()}
+		}
+	}
+	return NewTriage("ComponentStatuses", "Found unhealthy components!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
 
+// failedHealthChecks queries a verbose API server health endpoint and returns the checks that failed,
+// ok is false when the server does not serve the endpoint
+func failedHealthChecks(coreClient coreclient.CoreV1Interface, endpoint string) (failed []string, ok bool) {
+	// a failing endpoint answers with an error status but still lists every check in the body
+	raw, _ := coreClient.RESTClient().Get().AbsPath(endpoint).Param("verbose", "true").DoRaw()
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[+]") {
+			ok = true
+		} else if strings.HasPrefix(line, "[-]") {
+			ok = true
+			failed = append(failed, strings.TrimPrefix(line, "[-]"))
+		}
+	}
+	return failed, ok
+}
+
+// podReady reports whether the Ready condition of a pod is True
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podNotReadyReason describes why a pod is not ready from its phase and container states
+func podNotReadyReason(pod *corev1.Pod) string {
+	reasons := []string{"phase " + string(pod.Status.Phase)}
+	for _, c := range pod.Status.ContainerStatuses {
+		if w := c.State.Waiting; w != nil {
+			reasons = append(reasons, c.Name+" waiting: "+w.Reason)
+		} else if t := c.State.Terminated; t != nil {
+			reasons = append(reasons, c.Name+" terminated: "+t.Reason)
+		} else if !c.Ready {
+			reasons = append(reasons, c.Name+" not ready")
+		}
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// apiService mirrors the parts of an apiregistration.k8s.io APIService doctor needs,
+// decoded by hand so that doctor does not depend on the kube-aggregator client
+type apiService struct {
+	v1.ObjectMeta `json:"metadata,omitempty"`
+	Spec          struct {
+		Service *struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"service,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason,omitempty"`
+			Message string `json:"message,omitempty"`
+		} `json:"conditions,omitempty"`
+	} `json:"status"`
+}
+
+type apiServiceList struct {
+	Items []apiService `json:"items"`
+}
+
+// TriageAPIServices gets a kubernetes.Clientset and checks if any aggregated APIService is not Available,
+// which breaks discovery for every client, reporting the condition and the state of its backing service
+func TriageAPIServices(kubeCli *kubernetes.Clientset) (*Triage, error) {
 	listOfTriages := make([]string, 0)
-	for _, i := range components.Items {
-		for _, y := range i.Conditions {
-			if y.Status != componentHealthy {
-				listOfTriages = append(listOfTriages, i.GetName())
+	objects := make(map[string]ObjectRef)
+	raw, err := kubeCli.CoreV1().RESTClient().Get().AbsPath("/apis/apiregistration.k8s.io/v1/apiservices").DoRaw()
+	if err != nil {
+		if !strings.Contains(err.Error(), KUBE_RESOURCE_NOT_FOUND) {
+			return nil, err
+		}
+		return NewTriage("APIServices", "Found unavailable aggregated APIServices!", listOfTriages), nil
+	}
+	apiServices := &apiServiceList{}
+	if err := json.Unmarshal(raw, apiServices); err != nil {
+		return nil, err
+	}
+
+	for _, i := range apiServices.Items {
+		for _, c := range i.Status.Conditions {
+			if c.Type != apiServiceAvailable || c.Status == componentHealthy {
+				continue
+			}
+			anomaly := i.GetName() + ": " + c.Reason + " (" + c.Message + ")"
+			if svc := i.Spec.Service; svc != nil {
+				ready, err := serviceHasReadyEndpoints(kubeCli, svc.Namespace, svc.Name)
+				if err != nil {
+					return nil, err
+				}
+				if ready {
+					anomaly += ", service " + svc.Namespace + "/" + svc.Name + " has ready endpoints"
+				} else {
+					anomaly += ", service " + svc.Namespace + "/" + svc.Name + " is missing or has no ready endpoints"
+				}
 			}
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"APIService", "", i.GetName()}
 		}
 	}
-	return NewTriage("ComponentStatuses", "Found unhealthy components!", listOfTriages), nil
+	return NewTriage("APIServices", "Found unavailable aggregated APIServices!", listOfTriages).WithObjects(objects).AsCausal(), nil
 }
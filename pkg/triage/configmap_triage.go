@@ -0,0 +1,145 @@
+package triage
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// annotation of the configmaps used as leader election locks, which no pod references
+const leaderElectionAnnotation = "control-plane.alpha.kubernetes.io/leader"
+
+// namespaces holding the configuration of the control plane and of the kubelets, which pods do not reference
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// ConfigMaps published by the control plane into every namespace
+var systemConfigMaps = map[string]bool{
+	"kube-root-ca.crt": true,
+}
+
+// Secret types managed by the control plane or by tools that do not reference them from pods
+var systemSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	corev1.SecretTypeBootstrapToken:      true,
+	"helm.sh/release.v1":                 true,
+}
+
+// LeftOverConfigMaps gets a kubernetes.Clientset, a dynamic client, a specific namespace string and a duration
+// then proceeds to search if there are leftover configmaps
+// the criteria is that no pod, workload, job or cronjob references them and they are older than the duration
+func LeftOverConfigMaps(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	if systemNamespaces[namespace] {
+		return NewTriage("ConfigMaps", "Found leftover configmaps in namespace: "+namespace, listOfTriages), nil
+	}
+	configMaps, err := kubeCli.CoreV1().ConfigMaps(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	if len(configMaps.Items) == 0 {
+		return NewTriage("ConfigMaps", "Found leftover configmaps in namespace: "+namespace, listOfTriages), nil
+	}
+
+	specs, err := listPodSpecs(kubeCli, dynamicCli, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool)
+	for _, spec := range specs {
+		for name := range collectPodReferences(spec).ConfigMaps {
+			referenced[name] = true
+		}
+	}
+
+	currentTime := time.Now()
+	for _, i := range configMaps.Items {
+		if referenced[i.GetName()] || systemConfigMaps[i.GetName()] {
+			continue
+		}
+		if _, leaderLock := i.GetAnnotations()[leaderElectionAnnotation]; leaderLock {
+			continue
+		}
+		if currentTime.Sub(i.GetCreationTimestamp().Time) > threshold {
+			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"ConfigMap", namespace, i.GetName()}
+		}
+	}
+	return NewTriage("ConfigMaps", "Found leftover configmaps in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// LeftOverSecrets gets a kubernetes.Clientset, a dynamic client, a specific namespace string and a duration
+// then proceeds to search if there are leftover secrets
+// the criteria is that no pod, workload, job, cronjob, serviceaccount or ingress tls references them
+// and they are older than the duration
+func LeftOverSecrets(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	if systemNamespaces[namespace] {
+		return NewTriage("Secrets", "Found leftover secrets in namespace: "+namespace, listOfTriages), nil
+	}
+	secrets, err := kubeCli.CoreV1().Secrets(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	if len(secrets.Items) == 0 {
+		return NewTriage("Secrets", "Found leftover secrets in namespace: "+namespace, listOfTriages), nil
+	}
+
+	specs, err := listPodSpecs(kubeCli, dynamicCli, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool)
+	for _, spec := range specs {
+		for name := range collectPodReferences(spec).Secrets {
+			referenced[name] = true
+		}
+	}
+
+	serviceAccounts, err := kubeCli.CoreV1().ServiceAccounts(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range serviceAccounts.Items {
+		for _, s := range i.Secrets {
+			referenced[s.Name] = true
+		}
+		for _, s := range i.ImagePullSecrets {
+			referenced[s.Name] = true
+		}
+	}
+
+	tlsSecrets, err := ingressTLSSecrets(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range tlsSecrets {
+		referenced[name] = true
+	}
+
+	currentTime := time.Now()
+	for _, i := range secrets.Items {
+		if referenced[i.GetName()] || systemSecretTypes[i.Type] {
+			continue
+		}
+		if currentTime.Sub(i.GetCreationTimestamp().Time) > threshold {
+			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"Secret", namespace, i.GetName()}
+		}
+	}
+	return NewTriage("Secrets", "Found leftover secrets in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
@@ -0,0 +1,216 @@
+package triage
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// finding locates a single anomaly within the report
+type finding struct {
+	triage  *Triage
+	anomaly string
+}
+
+func (f finding) String() string {
+	return f.triage.ResourceType + ": " + f.anomaly
+}
+
+// dependencyGraph links every object to the objects it depends on, its owners through ownerReferences
+// and, for services and their endpoints, the pods and workloads their selector matches
+type dependencyGraph struct {
+	*ownerGraph
+	selects map[ObjectRef][]ObjectRef
+}
+
+// buildDependencyGraph gets a kubernetes.Clientset and extends the owner graph with the pods and workloads
+// selected by every service across all namespaces
+func buildDependencyGraph(kubeCli *kubernetes.Clientset, owners *ownerGraph) (*dependencyGraph, error) {
+	g := &dependencyGraph{owners, make(map[ObjectRef][]ObjectRef)}
+
+	services, err := kubeCli.CoreV1().Services("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	pods, err := kubeCli.CoreV1().Pods("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	workloads, err := listWorkloads(kubeCli, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// namespace -> labels of the pods and workload templates in it
+	candidates := make(map[string][]labeledObject)
+	for _, p := range pods.Items {
+		candidates[p.GetNamespace()] = append(candidates[p.GetNamespace()],
+			labeledObject{ObjectRef{"Pod", p.GetNamespace(), p.GetName()}, p.GetLabels()})
+	}
+	for _, w := range workloads {
+		candidates[w.Namespace] = append(candidates[w.Namespace], labeledObject{w.ref(), w.Template.GetLabels()})
+	}
+
+	for _, i := range services.Items {
+		// services without a selector have their endpoints managed by hand
+		if len(i.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(i.Spec.Selector)
+		selected := make([]ObjectRef, 0)
+		for _, c := range candidates[i.GetNamespace()] {
+			if selector.Matches(labels.Set(c.labels)) {
+				selected = append(selected, c.ref)
+			}
+		}
+		g.selects[ObjectRef{"Service", i.GetNamespace(), i.GetName()}] = selected
+		g.selects[ObjectRef{"Endpoints", i.GetNamespace(), i.GetName()}] = selected
+	}
+	return g, nil
+}
+
+// labeledObject is a pod or workload a service selector may match
+type labeledObject struct {
+	ref    ObjectRef
+	labels map[string]string
+}
+
+// dependencies returns the objects ref directly depends on
+func (g *dependencyGraph) dependencies(ref ObjectRef) []ObjectRef {
+	return append(append([]ObjectRef{}, g.owners[ref]...), g.selects[ref]...)
+}
+
+// nearestCause walks the dependencies of ref breadth first and returns the closest one
+// that has findings of its own
+func (g *dependencyGraph) nearestCause(ref ObjectRef, findings map[ObjectRef][]finding) (ObjectRef, bool) {
+	seen := map[ObjectRef]bool{ref: true}
+	queue := g.dependencies(ref)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		if len(findings[current]) > 0 {
+			return current, true
+		}
+		queue = append(queue, g.dependencies(current)...)
+	}
+	return ObjectRef{}, false
+}
+
+// causalFindings indexes the availability and health findings of the report by the object they were found on
+func causalFindings(triages []*Triage) map[ObjectRef][]finding {
+	findings := make(map[ObjectRef][]finding)
+	for _, t := range triages {
+		if !t.Causal {
+			continue
+		}
+		for _, anomaly := range t.Anomalies {
+			if ref, ok := t.Objects[anomaly]; ok {
+				findings[ref] = append(findings[ref], finding{t, anomaly})
+			}
+		}
+	}
+	return findings
+}
+
+// CorrelateFindings gets a kubernetes.Clientset, the object walk and the report, links the availability and health findings
+// through the objects they were found on and collapses every such finding whose object depends on another
+// object with such findings beneath the root cause, an orphaned Deployment then carries its orphaned ReplicaSet
+// and the service left without endpoints as symptoms. Hygiene findings (image tags, limits, probes, ...) are
+// neither causes nor symptoms. Triages left without anomalies are dropped from the report
+func CorrelateFindings(kubeCli *kubernetes.Clientset, walk *ObjectWalk, triages []*Triage) ([]*Triage, error) {
+	if len(causalFindings(triages)) == 0 {
+		return triages, nil
+	}
+	graph, err := buildDependencyGraph(kubeCli, walk.owners)
+	if err != nil {
+		return nil, err
+	}
+	return correlate(graph, triages), nil
+}
+
+// correlate moves every causal finding whose object depends on another object with causal findings
+// under the root cause of its chain in the dependency graph
+func correlate(graph *dependencyGraph, triages []*Triage) []*Triage {
+	findings := causalFindings(triages)
+	// resolve every object with findings to the root of its chain of causes
+	roots := make(map[ObjectRef]ObjectRef)
+	for ref := range findings {
+		root, visited := ref, map[ObjectRef]bool{ref: true}
+		for {
+			cause, ok := graph.nearestCause(root, findings)
+			if !ok || visited[cause] {
+				break
+			}
+			visited[cause] = true
+			root = cause
+		}
+		if root != ref {
+			roots[ref] = root
+		}
+	}
+	// objects depending on each other in a cycle, and objects whose chain leads into one, are left as they are
+	cyclic := make([]ObjectRef, 0)
+	for ref, root := range roots {
+		if _, ok := roots[root]; ok {
+			cyclic = append(cyclic, ref)
+		}
+	}
+	for _, ref := range cyclic {
+		delete(roots, ref)
+	}
+	if len(roots) == 0 {
+		return triages
+	}
+
+	symptoms := make(map[*Triage]map[string]bool)
+	for _, t := range triages {
+		if !t.Causal {
+			continue
+		}
+		for _, anomaly := range t.Anomalies {
+			ref, ok := t.Objects[anomaly]
+			if !ok {
+				continue
+			}
+			root, ok := roots[ref]
+			if !ok {
+				continue
+			}
+			// the first finding about the root cause in report order carries the symptoms
+			cause := findings[root][0]
+			if cause.triage.Symptoms == nil {
+				cause.triage.Symptoms = make(map[string][]string)
+			}
+			cause.triage.Symptoms[cause.anomaly] = append(cause.triage.Symptoms[cause.anomaly], finding{t, anomaly}.String())
+			if symptoms[t] == nil {
+				symptoms[t] = make(map[string]bool)
+			}
+			symptoms[t][anomaly] = true
+		}
+	}
+
+	correlated := make([]*Triage, 0, len(triages))
+	for _, t := range triages {
+		if len(symptoms[t]) > 0 {
+			anomalies := make([]string, 0, len(t.Anomalies))
+			for _, anomaly := range t.Anomalies {
+				if !symptoms[t][anomaly] {
+					anomalies = append(anomalies, anomaly)
+				}
+			}
+			t.Anomalies = anomalies
+		}
+		if len(t.Anomalies) > 0 {
+			correlated = append(correlated, t)
+		}
+	}
+	return correlated
+}
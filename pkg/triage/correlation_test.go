@@ -0,0 +1,120 @@
+package triage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCorrelate(t *testing.T) {
+	deployment := ObjectRef{"Deployment", "ns", "web"}
+	replicaSet := ObjectRef{"ReplicaSet", "ns", "web-1"}
+	pod := ObjectRef{"Pod", "ns", "web-1-a"}
+	endpoints := ObjectRef{"Endpoints", "ns", "web"}
+	a := ObjectRef{"Widget", "ns", "a"}
+	b := ObjectRef{"Widget", "ns", "b"}
+	c := ObjectRef{"Widget", "ns", "c"}
+
+	tests := []struct {
+		name string
+		// child -> owners it depends on
+		owners map[ObjectRef][]ObjectRef
+		// service or endpoints -> objects its selector matches
+		selects map[ObjectRef][]ObjectRef
+		causal  []ObjectRef
+		hygiene []ObjectRef
+		// anomalies left in the report, with the symptoms they carry
+		want map[string][]string
+	}{
+		{
+			name:   "chain collapses under its root",
+			owners: map[ObjectRef][]ObjectRef{replicaSet: {deployment}, pod: {replicaSet}},
+			causal: []ObjectRef{deployment, replicaSet, pod},
+			want: map[string][]string{
+				deployment.String(): {"ReplicaSet: " + replicaSet.String(), "Pod: " + pod.String()},
+			},
+		},
+		{
+			name:    "chain through a service selector",
+			owners:  map[ObjectRef][]ObjectRef{pod: {replicaSet}},
+			selects: map[ObjectRef][]ObjectRef{endpoints: {pod}},
+			causal:  []ObjectRef{replicaSet, endpoints},
+			want: map[string][]string{
+				replicaSet.String(): {"Endpoints: " + endpoints.String()},
+			},
+		},
+		{
+			name:   "chain skips objects without findings",
+			owners: map[ObjectRef][]ObjectRef{replicaSet: {deployment}, pod: {replicaSet}},
+			causal: []ObjectRef{deployment, pod},
+			want: map[string][]string{
+				deployment.String(): {"Pod: " + pod.String()},
+			},
+		},
+		{
+			name:   "cycle is left as it is",
+			owners: map[ObjectRef][]ObjectRef{a: {b}, b: {a}},
+			causal: []ObjectRef{a, b},
+			want: map[string][]string{
+				a.String(): nil,
+				b.String(): nil,
+			},
+		},
+		{
+			name:   "chain into a cycle is left as it is",
+			owners: map[ObjectRef][]ObjectRef{c: {a}, a: {b}, b: {a}},
+			causal: []ObjectRef{a, b, c},
+			want: map[string][]string{
+				a.String(): nil,
+				b.String(): nil,
+				c.String(): nil,
+			},
+		},
+		{
+			name:    "hygiene findings are neither causes nor symptoms",
+			owners:  map[ObjectRef][]ObjectRef{replicaSet: {deployment}, pod: {replicaSet}},
+			causal:  []ObjectRef{pod},
+			hygiene: []ObjectRef{deployment, replicaSet},
+			want: map[string][]string{
+				deployment.String(): nil,
+				replicaSet.String(): nil,
+				pod.String():        nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owners := newOwnerGraph()
+			for child, parents := range tt.owners {
+				for _, parent := range parents {
+					owners.link(parent, child)
+				}
+			}
+			selects := tt.selects
+			if selects == nil {
+				selects = make(map[ObjectRef][]ObjectRef)
+			}
+			graph := &dependencyGraph{owners, selects}
+
+			triages := make([]*Triage, 0)
+			for _, ref := range tt.causal {
+				triages = append(triages, NewTriage(ref.Kind, "causal", []string{ref.String()}).
+					WithObjects(map[string]ObjectRef{ref.String(): ref}).AsCausal())
+			}
+			for _, ref := range tt.hygiene {
+				triages = append(triages, NewTriage(ref.Kind, "hygiene", []string{ref.String()}).
+					WithObjects(map[string]ObjectRef{ref.String(): ref}))
+			}
+
+			got := make(map[string][]string)
+			for _, triage := range correlate(graph, triages) {
+				for _, anomaly := range triage.Anomalies {
+					got[anomaly] = triage.Symptoms[anomaly]
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package triage
+
+import (
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want []string
+	}{
+		{"dst gap skips the missing run", "CRON_TZ=America/New_York 30 2 * * *", "2026-03-07T12:00:00Z",
+			[]string{"2026-03-09T06:30:00Z"}},
+		{"dst gap run right after the gap", "CRON_TZ=America/New_York 0 3 * * *", "2026-03-07T12:00:00Z",
+			[]string{"2026-03-08T07:00:00Z", "2026-03-09T07:00:00Z"}},
+		{"dst gap every minute", "TZ=America/New_York * * * * *", "2026-03-08T06:59:00Z",
+			[]string{"2026-03-08T07:00:00Z", "2026-03-08T07:01:00Z"}},
+		{"dst overlap runs twice", "CRON_TZ=America/New_York 30 1 * * *", "2026-11-01T05:00:00Z",
+			[]string{"2026-11-01T05:30:00Z", "2026-11-01T06:30:00Z", "2026-11-02T06:30:00Z"}},
+		{"step", "*/15 * * * *", "2026-01-01T10:07:00Z",
+			[]string{"2026-01-01T10:15:00Z", "2026-01-01T10:30:00Z"}},
+		{"step from a value", "5/20 * * * *", "2026-01-01T10:26:00Z",
+			[]string{"2026-01-01T10:45:00Z", "2026-01-01T11:05:00Z"}},
+		{"range", "0 9-17 * * *", "2026-01-01T17:30:00Z",
+			[]string{"2026-01-02T09:00:00Z"}},
+		{"list", "0 0 1,15 * *", "2026-01-02T00:00:00Z",
+			[]string{"2026-01-15T00:00:00Z", "2026-02-01T00:00:00Z"}},
+		{"month names", "0 0 1 jan,jul *", "2026-01-02T00:00:00Z",
+			[]string{"2026-07-01T00:00:00Z"}},
+		{"day of month or day of week", "0 0 13 * 5", "2026-01-01T00:00:00Z",
+			[]string{"2026-01-02T00:00:00Z", "2026-01-09T00:00:00Z", "2026-01-13T00:00:00Z"}},
+		{"day of week only", "0 0 * * fri", "2026-01-10T00:00:00Z",
+			[]string{"2026-01-16T00:00:00Z"}},
+		{"day of month only", "0 0 13 * *", "2026-01-01T00:00:00Z",
+			[]string{"2026-01-13T00:00:00Z"}},
+		{"every", "@every 90m", "2026-01-01T10:00:30Z",
+			[]string{"2026-01-01T11:30:30Z", "2026-01-01T13:00:30Z"}},
+		{"daily", "@daily", "2026-01-01T10:00:00Z",
+			[]string{"2026-01-02T00:00:00Z"}},
+		{"hourly", "@hourly", "2026-01-01T10:00:00Z",
+			[]string{"2026-01-01T11:00:00Z"}},
+		{"weekly", "@weekly", "2026-01-01T10:00:00Z",
+			[]string{"2026-01-04T00:00:00Z"}},
+		{"yearly", "@yearly", "2026-01-01T10:00:00Z",
+			[]string{"2027-01-01T00:00:00Z"}},
+		{"never", "0 0 30 2 *", "2026-01-01T00:00:00Z",
+			[]string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.spec, err)
+			}
+			current := mustTime(t, tt.from)
+			for _, want := range tt.want {
+				got := schedule.next(current)
+				if want == "" {
+					if !got.IsZero() {
+						t.Fatalf("next(%s) = %s, want none", current, got)
+					}
+					return
+				}
+				if !got.Equal(mustTime(t, want)) {
+					t.Fatalf("next(%s) = %s, want %s", current, got.UTC().Format(time.RFC3339), want)
+				}
+				current = got
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"* * * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every -1m",
+		"@every often",
+		"CRON_TZ=Nowhere/Zone 0 0 * * *",
+	} {
+		if _, err := parseCronSchedule(spec, time.UTC); err == nil {
+			t.Errorf("parse %q: expected an error", spec)
+		}
+	}
+}
+
+func TestLatestScheduledRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		since string
+		now   string
+		want  string
+	}{
+		{"across a dst gap", "CRON_TZ=America/New_York 30 2 * * *", "2026-03-07T12:00:00Z", "2026-03-10T00:00:00Z",
+			"2026-03-09T06:30:00Z"},
+		{"not due yet", "0 0 * * *", "2026-01-01T00:00:00Z", "2026-01-01T23:00:00Z", ""},
+		{"long overdue", "0 0 1 1 *", "2024-06-01T00:00:00Z", "2026-06-01T00:00:00Z", "2026-01-01T00:00:00Z"},
+		{"frequent", "*/5 * * * *", "2026-01-01T00:00:00Z", "2026-02-01T00:02:00Z", "2026-02-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := latestScheduledRun(schedule, mustTime(t, tt.since), mustTime(t, tt.now))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Fatalf("got %s, want none", got)
+				}
+				return
+			}
+			if !got.Equal(mustTime(t, tt.want)) {
+				t.Fatalf("got %s, want %s", got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
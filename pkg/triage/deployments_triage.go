@@ -11,6 +11,7 @@ import (
 // the criteria is that the desired number of replicas are bigger than 0 but the available replicas are 0
 func OrphanedDeployments(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	deployments, err := kubeCli.ExtensionsV1beta1().Deployments(namespace).List(v1.ListOptions{})
 	if err != nil {
 		if ! strings.Contains(err.Error(), KUBE_RESOURCE_NOT_FOUND) {
@@ -20,9 +21,10 @@ func OrphanedDeployments(kubeCli *kubernetes.Clientset, namespace string) (*Tria
 	for _, i := range deployments.Items {
 		if i.Status.Replicas > 0 && i.Status.AvailableReplicas == 0 {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"Deployment", namespace, i.GetName()}
 		}
 	}
-	return NewTriage("Deployments", "Found orphan deployments in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("Deployments", "Found orphan deployments in namespace: "+namespace, listOfTriages).WithObjects(objects).AsCausal(), nil
 }
 
 // LeftOverDeployments gets a kubernetes.Clientset and a specific namespace string
@@ -30,6 +32,7 @@ func OrphanedDeployments(kubeCli *kubernetes.Clientset, namespace string) (*Tria
 // the criteria is that both the desired number of replicas and the available # of replicas are 0
 func LeftOverDeployments(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	deployments, err := kubeCli.ExtensionsV1beta1().Deployments(namespace).List(v1.ListOptions{})
 	if err != nil {
 		if ! strings.Contains(err.Error(), KUBE_RESOURCE_NOT_FOUND) {
@@ -40,7 +43,8 @@ func LeftOverDeployments(kubeCli *kubernetes.Clientset, namespace string) (*Tria
 	for _, i := range deployments.Items {
 		if i.Status.Replicas == 0 && i.Status.AvailableReplicas == 0 {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"Deployment", namespace, i.GetName()}
 		}
 	}
-	return NewTriage("Deployments", "Found leftover deployments in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("Deployments", "Found leftover deployments in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
 }
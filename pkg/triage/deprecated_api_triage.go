@@ -0,0 +1,189 @@
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// apiRemoval is a deprecated API version and the kubernetes 1.x minor version that stops serving it
+type apiRemoval struct {
+	GroupVersion string
+	// Kind is empty when every kind of the group version is removed in the same release
+	Kind      string
+	RemovedIn int
+}
+
+// apiRemovals is the table of deprecated API versions, see
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var apiRemovals = []apiRemoval{
+	{"extensions/v1beta1", "Ingress", 22},
+	{"extensions/v1beta1", "", 16},
+	{"apps/v1beta1", "", 16},
+	{"apps/v1beta2", "", 16},
+	{"admissionregistration.k8s.io/v1beta1", "", 22},
+	{"apiextensions.k8s.io/v1beta1", "", 22},
+	{"apiregistration.k8s.io/v1beta1", "", 22},
+	{"authentication.k8s.io/v1beta1", "", 22},
+	{"authorization.k8s.io/v1beta1", "", 22},
+	{"certificates.k8s.io/v1beta1", "", 22},
+	{"coordination.k8s.io/v1beta1", "", 22},
+	{"networking.k8s.io/v1beta1", "", 22},
+	{"rbac.authorization.k8s.io/v1beta1", "", 22},
+	{"scheduling.k8s.io/v1beta1", "", 22},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", 27},
+	{"storage.k8s.io/v1beta1", "", 22},
+	{"batch/v1beta1", "", 25},
+	{"discovery.k8s.io/v1beta1", "", 25},
+	{"events.k8s.io/v1beta1", "", 25},
+	{"autoscaling/v2beta1", "", 25},
+	{"policy/v1beta1", "", 25},
+	{"node.k8s.io/v1beta1", "", 25},
+	{"autoscaling/v2beta2", "", 26},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "", 26},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "", 29},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "", 32},
+}
+
+// matches one sample of the apiserver_requested_deprecated_apis metric
+var deprecatedAPIMetric = regexp.MustCompile(`^apiserver_requested_deprecated_apis\{([^}]*)\}`)
+
+// removalRelease looks up the 1.x minor version removing the given apiVersion and kind, 0 if it is not deprecated
+func removalRelease(apiVersion, kind string) int {
+	for _, r := range apiRemovals {
+		if r.GroupVersion == apiVersion && (r.Kind == "" || r.Kind == kind) {
+			return r.RemovedIn
+		}
+	}
+	return 0
+}
+
+// serverMinor returns the minor version of the targeted server, managed offerings report versions like "14+"
+func serverMinor(kubeCli *kubernetes.Clientset) (int, error) {
+	version, err := kubeCli.Discovery().ServerVersion()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimRight(version.Minor, "+"))
+}
+
+// deprecatedAPIsCheck searches objects that are still written with API versions removed within a number of
+// releases after the server's version, using their last-applied-configuration annotation and managedFields.
+// The apiserver_requested_deprecated_apis metric is reported as well when the /metrics endpoint is accessible
+type deprecatedAPIsCheck struct {
+	kubeCli       *kubernetes.Clientset
+	removedBy     int
+	listOfTriages []string
+	objects       map[string]ObjectRef
+}
+
+func newDeprecatedAPIsCheck(kubeCli *kubernetes.Clientset, window int) (*deprecatedAPIsCheck, error) {
+	minor, err := serverMinor(kubeCli)
+	if err != nil {
+		return nil, err
+	}
+	return &deprecatedAPIsCheck{kubeCli, minor + window, make([]string, 0), make(map[string]ObjectRef)}, nil
+}
+
+func (c *deprecatedAPIsCheck) visit(resource v1.APIResource, object *unstructured.Unstructured) {
+	// apiVersion -> where it was seen
+	usages := make(map[string][]string)
+
+	if lastApplied, ok := object.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok {
+		applied := struct {
+			APIVersion string `json:"apiVersion"`
+		}{}
+		if json.Unmarshal([]byte(lastApplied), &applied) == nil && applied.APIVersion != "" {
+			usages[applied.APIVersion] = append(usages[applied.APIVersion], "last-applied-configuration")
+		}
+	}
+	managedFields, _, _ := unstructured.NestedSlice(object.Object, "metadata", "managedFields")
+	for _, f := range managedFields {
+		entry, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		apiVersion, _ := entry["apiVersion"].(string)
+		manager, _ := entry["manager"].(string)
+		if apiVersion != "" {
+			usages[apiVersion] = append(usages[apiVersion], "managedFields by "+manager)
+		}
+	}
+
+	for apiVersion, sources := range usages {
+		removedIn := removalRelease(apiVersion, resource.Kind)
+		if removedIn == 0 || removedIn > c.removedBy {
+			continue
+		}
+		anomaly := fmt.Sprintf("%s: %s removed in 1.%d (%s)",
+			objectKey(resource.Kind, object), apiVersion, removedIn, strings.Join(sources, ", "))
+		c.listOfTriages = append(c.listOfTriages, anomaly)
+		c.objects[anomaly] = ObjectRef{resource.Kind, object.GetNamespace(), object.GetName()}
+	}
+}
+
+func (c *deprecatedAPIsCheck) triage() (*Triage, error) {
+	metrics, err := c.kubeCli.CoreV1().RESTClient().Get().AbsPath("/metrics").DoRaw()
+	if err != nil {
+		log.Warn("could not read apiserver metrics, skipping apiserver_requested_deprecated_apis: ", err)
+	} else {
+		c.listOfTriages = append(c.listOfTriages, requestedDeprecatedAPIs(string(metrics), c.removedBy)...)
+	}
+
+	sort.Strings(c.listOfTriages)
+	anomalyType := fmt.Sprintf("Found objects written with API versions removed by kubernetes 1.%d!", c.removedBy)
+	return NewTriage("DeprecatedAPIs", anomalyType, c.listOfTriages).WithObjects(c.objects), nil
+}
+
+// requestedDeprecatedAPIs extracts from the apiserver metrics the deprecated APIs that clients requested
+// and that are removed by the given 1.x minor version, samples without a parsable removed_release are kept
+func requestedDeprecatedAPIs(metrics string, removedBy int) []string {
+	requested := make([]string, 0)
+	for _, line := range strings.Split(metrics, "\n") {
+		match := deprecatedAPIMetric.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		labels := make(map[string]string)
+		for _, pair := range strings.Split(match[1], ",") {
+			if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+				labels[kv[0]] = strings.Trim(kv[1], `"`)
+			}
+		}
+		if removedIn, ok := parseMinor(labels["removed_release"]); ok && removedIn > removedBy {
+			continue
+		}
+		groupVersion := labels["version"]
+		if labels["group"] != "" {
+			groupVersion = labels["group"] + "/" + groupVersion
+		}
+		anomaly := "requested " + groupVersion + " " + labels["resource"]
+		if labels["subresource"] != "" {
+			anomaly += "/" + labels["subresource"]
+		}
+		if labels["removed_release"] != "" {
+			anomaly += " removed in " + labels["removed_release"]
+		}
+		requested = append(requested, anomaly+" (apiserver_requested_deprecated_apis)")
+	}
+	return requested
+}
+
+// parseMinor returns the minor version of a release like "1.22"
+func parseMinor(release string) (int, bool) {
+	parts := strings.Split(release, ".")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	return minor, err == nil
+}
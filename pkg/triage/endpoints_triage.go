@@ -15,10 +15,13 @@ func TriageEndpoints(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 	}
 
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	for _, i := range endpoints.Items {
 		if len(i.Subsets) == 0 {
-			listOfTriages = append(listOfTriages, i.GetName())
+			anomaly := i.GetNamespace() + "/" + i.GetName()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Endpoints", i.GetNamespace(), i.GetName()}
 		}
 	}
-	return NewTriage("Endpoints", "Found orphaned endpoints!", listOfTriages), nil
+	return NewTriage("Endpoints", "Found orphaned endpoints!", listOfTriages).WithObjects(objects).AsCausal(), nil
 }
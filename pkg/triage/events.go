@@ -0,0 +1,191 @@
+package triage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maximum number of events attached as evidence to a single anomaly
+const maxEvidence = 5
+
+// warningEvent aggregates the Warning events of one object for one reason
+type warningEvent struct {
+	Object  ObjectRef
+	Reason  string
+	Count   int32
+	Message string
+	Last    time.Time
+}
+
+func (e *warningEvent) String() string {
+	return fmt.Sprintf("%s x%d, last %s ago: %s", e.Reason, e.Count, time.Since(e.Last).Round(time.Second), e.Message)
+}
+
+// WarningEvents holds the Warning events of the cluster seen within a lookback window,
+// aggregated by involved object and reason
+type WarningEvents struct {
+	aggregates []*warningEvent
+	byObject   map[ObjectRef][]*warningEvent
+}
+
+func (w *WarningEvents) add(object corev1.ObjectReference, reason, message string, count int32, last time.Time) {
+	ref := ObjectRef{object.Kind, object.Namespace, object.Name}
+	for _, e := range w.byObject[ref] {
+		if e.Reason == reason {
+			e.Count += count
+			if last.After(e.Last) {
+				e.Last = last
+				e.Message = message
+			}
+			return
+		}
+	}
+	e := &warningEvent{ref, reason, count, message, last}
+	w.aggregates = append(w.aggregates, e)
+	w.byObject[ref] = append(w.byObject[ref], e)
+}
+
+// CollectWarningEvents gets a kubernetes.Clientset and gathers the Warning events of all namespaces
+// last seen within lookback, from both the core/v1 and the events.k8s.io APIs
+func CollectWarningEvents(kubeCli *kubernetes.Clientset, lookback time.Duration) (*WarningEvents, error) {
+	events := &WarningEvents{byObject: make(map[ObjectRef][]*warningEvent)}
+	since := time.Now().Add(-lookback)
+	// both APIs serve the same underlying events, the uid tells them apart
+	seen := make(map[types.UID]bool)
+
+	coreEvents, err := kubeCli.CoreV1().Events("").List(v1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range coreEvents.Items {
+		seen[i.GetUID()] = true
+		last, count := i.LastTimestamp.Time, i.Count
+		if last.IsZero() {
+			last = i.EventTime.Time
+		}
+		if s := i.Series; s != nil {
+			last, count = s.LastObservedTime.Time, s.Count
+		}
+		if last.IsZero() {
+			last = i.CreationTimestamp.Time
+		}
+		if last.Before(since) {
+			continue
+		}
+		if count == 0 {
+			count = 1
+		}
+		events.add(i.InvolvedObject, i.Reason, i.Message, count, last)
+	}
+
+	newEvents, err := kubeCli.EventsV1beta1().Events("").List(v1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range newEvents.Items {
+		if seen[i.GetUID()] || i.Type != corev1.EventTypeWarning {
+			continue
+		}
+		last, count := i.EventTime.Time, i.DeprecatedCount
+		if s := i.Series; s != nil {
+			last, count = s.LastObservedTime.Time, s.Count
+		}
+		if last.IsZero() {
+			last = i.DeprecatedLastTimestamp.Time
+		}
+		if last.IsZero() {
+			last = i.CreationTimestamp.Time
+		}
+		if last.Before(since) {
+			continue
+		}
+		if count == 0 {
+			count = 1
+		}
+		events.add(i.Regarding, i.Reason, i.Note, count, last)
+	}
+
+	for _, e := range events.aggregates {
+		e.Message = strings.Join(strings.Fields(e.Message), " ")
+	}
+	return events, nil
+}
+
+// TriageWarningEvents ranks the objects and reasons that produced the most Warning events
+// and reports the top ones
+func TriageWarningEvents(events *WarningEvents, top int) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+
+	ranked := make([]*warningEvent, len(events.aggregates))
+	copy(ranked, events.aggregates)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Last.After(ranked[j].Last)
+	})
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	for _, e := range ranked {
+		anomaly := e.Object.String() + ": " + e.String()
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = e.Object
+	}
+	return NewTriage("Events", "Found objects emitting the most warning events!", listOfTriages).WithObjects(objects), nil
+}
+
+// AttachEvidence gets the object walk and adds to every anomaly found on a single object
+// the most recent Warning events of that object and of the objects it owns, so a Deployment
+// carries the FailedScheduling or BackOff events of its pods
+func AttachEvidence(walk *ObjectWalk, triages []*Triage, events *WarningEvents) {
+	if len(events.aggregates) == 0 {
+		return
+	}
+
+	for _, t := range triages {
+		for _, anomaly := range t.Anomalies {
+			ref, ok := t.Objects[anomaly]
+			if !ok {
+				continue
+			}
+			related := make([]*warningEvent, 0)
+			for _, object := range append([]ObjectRef{ref}, walk.owners.descendants(ref)...) {
+				related = append(related, events.byObject[object]...)
+			}
+			if len(related) == 0 {
+				continue
+			}
+			sort.SliceStable(related, func(i, j int) bool {
+				return related[i].Last.After(related[j].Last)
+			})
+			if len(related) > maxEvidence {
+				related = related[:maxEvidence]
+			}
+			evidence := make([]string, 0, len(related))
+			for _, e := range related {
+				if e.Object == ref {
+					evidence = append(evidence, e.String())
+				} else {
+					evidence = append(evidence, e.Object.String()+": "+e.String())
+				}
+			}
+			if t.Evidence == nil {
+				t.Evidence = make(map[string][]string)
+			}
+			t.Evidence[anomaly] = evidence
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// reason the HPA controller sets on ScalingLimited when the desired replicas are capped by maxReplicas
+const hpaTooManyReplicas = "TooManyReplicas"
+
+var (
+	hpasV2      = schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+	hpasV2beta2 = schema.GroupVersionResource{Group: "autoscaling", Version: "v2beta2", Resource: "horizontalpodautoscalers"}
+)
+
+// listHPAs gets a dynamic client and a specific namespace string then lists the HorizontalPodAutoscalers in it
+// from autoscaling/v2, falling back to autoscaling/v2beta2 on servers that predate it.
+// The v2beta2 type covers every field doctor reads from both versions
+func listHPAs(dynamicCli dynamic.Interface, namespace string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas := make([]autoscalingv2.HorizontalPodAutoscaler, 0)
+	list, err := dynamicCli.Resource(hpasV2).Namespace(namespace).List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		list, err = dynamicCli.Resource(hpasV2beta2).Namespace(namespace).List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return hpas, nil
+	}
+	for _, i := range list.Items {
+		hpa := autoscalingv2.HorizontalPodAutoscaler{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &hpa); err != nil {
+			return nil, err
+		}
+		hpas = append(hpas, hpa)
+	}
+	return hpas, nil
+}
+
+// TriageHPA gets a kubernetes.Clientset, a dynamic client, a specific namespace string and a duration
+// then proceeds to search HorizontalPodAutoscalers whose scale target does not exist, that were pinned at
+// maxReplicas for longer than the duration, that cannot fetch their metrics, whose target lacks the resource
+// requests a utilization metric needs, or that share their target with another HPA
+func TriageHPA(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string, pinnedThreshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	hpas, err := listHPAs(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	hpasByTarget := make(map[string][]string)
+	for _, i := range hpas {
+		ref := i.Spec.ScaleTargetRef
+		target := ref.Kind + "/" + ref.Name
+		hpasByTarget[target] = append(hpasByTarget[target], i.GetName())
+
+		issues := make([]string, 0)
+		template, err := getWorkloadTemplate(kubeCli, namespace, ref.Kind, ref.Name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			issues = append(issues, "scale target "+target+" does not exist")
+		}
+		if template != nil {
+			for _, r := range utilizationResources(i.Spec.Metrics) {
+				for _, c := range template.Spec.Containers {
+					if _, ok := c.Resources.Requests[r]; !ok {
+						issues = append(issues, "container "+c.Name+" of "+target+" has no "+string(r)+" request for utilization metric")
+					}
+				}
+			}
+		}
+
+		for _, c := range i.Status.Conditions {
+			if c.Type == autoscalingv2.ScalingActive && c.Status == corev1.ConditionFalse {
+				issues = append(issues, "ScalingActive=False "+c.Reason+" ("+c.Message+")")
+			}
+			if c.Type == autoscalingv2.ScalingLimited && c.Status == corev1.ConditionTrue && c.Reason == hpaTooManyReplicas &&
+				i.Status.CurrentReplicas == i.Spec.MaxReplicas {
+				if pinnedFor := currentTime.Sub(c.LastTransitionTime.Time); pinnedFor > pinnedThreshold {
+					issues = append(issues, "pinned at maxReplicas for "+pinnedFor.Round(time.Minute).String())
+				}
+			}
+		}
+		if len(issues) > 0 {
+			anomaly := i.GetName() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"HorizontalPodAutoscaler", namespace, i.GetName()}
+		}
+	}
+
+	targets := make([]string, 0, len(hpasByTarget))
+	for target := range hpasByTarget {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		if names := hpasByTarget[target]; len(names) > 1 {
+			listOfTriages = append(listOfTriages, target+": targeted by multiple HPAs "+strings.Join(names, ", "))
+		}
+	}
+	return NewTriage("HorizontalPodAutoscalers", "Found misconfigured or saturated HPAs in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// utilizationResources returns the resources an HPA scales on by utilization,
+// which are computed as a percentage of the containers' requests
+func utilizationResources(metrics []autoscalingv2.MetricSpec) []corev1.ResourceName {
+	resources := make([]corev1.ResourceName, 0)
+	for _, m := range metrics {
+		if m.Type == autoscalingv2.ResourceMetricSourceType && m.Resource != nil &&
+			m.Resource.Target.Type == autoscalingv2.UtilizationMetricType {
+			resources = append(resources, m.Resource.Name)
+		}
+	}
+	return resources
+}
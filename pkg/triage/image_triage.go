@@ -0,0 +1,167 @@
+package triage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultRegistry = "docker.io"
+
+// tags that are conventionally moved to newer images over time
+var mutableTags = map[string]bool{
+	"":        true,
+	"latest":  true,
+	"stable":  true,
+	"main":    true,
+	"master":  true,
+	"dev":     true,
+	"edge":    true,
+	"nightly": true,
+}
+
+// imageRef is a container image reference split into its parts
+type imageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseImage splits an image reference such as registry:5000/team/app:1.0@sha256:... into its parts
+func parseImage(image string) imageRef {
+	ref := imageRef{}
+	if at := strings.Index(image, "@"); at >= 0 {
+		ref.Digest = image[at+1:]
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		ref.Tag = image[colon+1:]
+		image = image[:colon]
+	}
+	ref.Registry = defaultRegistry
+	if slash := strings.Index(image, "/"); slash >= 0 {
+		host := image[:slash]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			image = image[slash+1:]
+		}
+	}
+	ref.Repository = image
+	return ref
+}
+
+// podContainers returns the init and app containers of a pod spec
+func podContainers(spec *corev1.PodSpec) []corev1.Container {
+	return append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+}
+
+// TriageImageTags gets a kubernetes.Clientset and a specific namespace string
+// then proceeds to search workloads running :latest or untagged images, and images with
+// mutable tags that are not pinned by digest but pulled with the IfNotPresent policy
+func TriageImageTags(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range workloads {
+		issues := make([]string, 0)
+		for _, c := range podContainers(&w.Template.Spec) {
+			ref := parseImage(c.Image)
+			if ref.Digest != "" {
+				continue
+			}
+			if ref.Tag == "" || ref.Tag == "latest" {
+				issues = append(issues, c.Name+" uses untagged or :latest image "+c.Image)
+			}
+			if mutableTags[ref.Tag] && c.ImagePullPolicy == corev1.PullIfNotPresent {
+				issues = append(issues, c.Name+" pulls mutable image "+c.Image+" with IfNotPresent policy")
+			}
+		}
+		if len(issues) > 0 {
+			anomaly := w.String() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = w.ref()
+		}
+	}
+	return NewTriage("Images", "Found workloads with unpinned image tags in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// TriageImageRegistries gets a kubernetes.Clientset, a specific namespace string and a list of allowed registries
+// then proceeds to search workloads pulling images from registries that are not allowed
+func TriageImageRegistries(kubeCli *kubernetes.Clientset, namespace string, allowedRegistries []string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool)
+	for _, r := range allowedRegistries {
+		allowed[r] = true
+	}
+	for _, w := range workloads {
+		images := make([]string, 0)
+		for _, c := range podContainers(&w.Template.Spec) {
+			if !allowed[parseImage(c.Image).Registry] {
+				images = append(images, c.Image)
+			}
+		}
+		if len(images) > 0 {
+			anomaly := w.String() + ": " + strings.Join(images, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = w.ref()
+		}
+	}
+	return NewTriage("Images", "Found workloads using images from registries not on the allowlist in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// TriageImageDrift gets a kubernetes.Clientset and checks across the whole cluster
+// for images that run with more than maxTags different tags (version drift)
+func TriageImageDrift(kubeCli *kubernetes.Clientset, maxTags int) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	workloads, err := listWorkloads(kubeCli, "")
+	if err != nil {
+		return nil, err
+	}
+
+	tagsByImage := make(map[string]map[string]bool)
+	for _, w := range workloads {
+		for _, c := range podContainers(&w.Template.Spec) {
+			ref := parseImage(c.Image)
+			name := ref.Registry + "/" + ref.Repository
+			version := ref.Tag
+			if ref.Digest != "" {
+				version = ref.Digest
+			}
+			if version == "" {
+				version = "latest"
+			}
+			if tagsByImage[name] == nil {
+				tagsByImage[name] = make(map[string]bool)
+			}
+			tagsByImage[name][version] = true
+		}
+	}
+
+	for name, tags := range tagsByImage {
+		if len(tags) <= maxTags {
+			continue
+		}
+		versions := make([]string, 0, len(tags))
+		for t := range tags {
+			versions = append(versions, t)
+		}
+		sort.Strings(versions)
+		listOfTriages = append(listOfTriages, fmt.Sprintf("%s: %d versions (%s)", name, len(versions), strings.Join(versions, ", ")))
+	}
+	sort.Strings(listOfTriages)
+	return NewTriage("Images", fmt.Sprintf("Found images running with more than %d different versions across the cluster!", maxTags), listOfTriages), nil
+}
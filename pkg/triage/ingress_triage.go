@@ -10,6 +10,7 @@ import (
 // then proceeds to search if there are leftover ingresses
 func LeftoverIngresses(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 
 	ingresses, err := kubeCli.NetworkingV1beta1().Ingresses(namespace).List(v1.ListOptions{})
 	if err != nil {
@@ -21,8 +22,9 @@ func LeftoverIngresses(kubeCli *kubernetes.Clientset, namespace string) (*Triage
 	for _, i := range ingresses.Items {
 		if i.Status.LoadBalancer.Size() <= 0 {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"Ingress", namespace, i.GetName()}
 		}
 
 	}
-	return NewTriage("Ingress", "Found leftover ingresses in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("Ingress", "Found leftover ingresses in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
 }
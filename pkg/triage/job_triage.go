@@ -1,17 +1,159 @@
 package triage
 
 import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"time"
 )
 
-// LeftoverJobs gets a kubernetes.Clientset and a specific namespace string
-// then proceeds to search if there are leftover cronjobs that were inactive for more than a month
-func LeftoverJobs(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
+// how late the cronjob controller may start a run that has no startingDeadlineSeconds before it counts as missed
+const cronJobScheduleGrace = 5 * time.Minute
+
+var (
+	cronJobsV1      = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	cronJobsV1beta1 = schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}
+)
+
+// listCronJobs gets a dynamic client and a specific namespace string then lists the cronjobs in it from batch/v1,
+// falling back to batch/v1beta1 on servers that predate it. Servers serving neither return an empty list
+func listCronJobs(dynamicCli dynamic.Interface, namespace string) (*unstructured.UnstructuredList, error) {
+	cronJobs, err := dynamicCli.Resource(cronJobsV1).Namespace(namespace).List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		cronJobs, err = dynamicCli.Resource(cronJobsV1beta1).Namespace(namespace).List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return &unstructured.UnstructuredList{}, nil
+	}
+	return cronJobs, nil
+}
+
+// LeftoverJobs gets a dynamic client and a specific namespace string
+// then proceeds to analyse the schedule of every cronjob: cronjobs whose schedule or time zone is invalid,
+// cronjobs that missed their most recent scheduled run (taking startingDeadlineSeconds and spec.timeZone
+// or a CRON_TZ= prefix into account) and cronjobs with concurrencyPolicy Allow that have overlapping active runs.
+// The dynamic client is used because the typed client doctor is built with predates spec.timeZone.
+// Without a time zone the controller runs schedules in the local time of kube-controller-manager, which
+// doctor cannot see, so they are evaluated in UTC, the usual time zone of control plane hosts
+func LeftoverJobs(dynamicCli dynamic.Interface, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+
+	cronJobs, err := listCronJobs(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	for idx := range cronJobs.Items {
+		cronJob := &cronJobs.Items[idx]
+		name := cronJob.GetName()
+		if suspend, _, _ := unstructured.NestedBool(cronJob.Object, "spec", "suspend"); suspend {
+			continue
+		}
+
+		// assumed to match kube-controller-manager's local time, see above
+		location := time.UTC
+		if zone, ok, _ := unstructured.NestedString(cronJob.Object, "spec", "timeZone"); ok && zone != "" {
+			if location, err = time.LoadLocation(zone); err != nil {
+				anomaly := name + ": invalid time zone " + zone
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"CronJob", namespace, name}
+				continue
+			}
+		}
+		spec, _, _ := unstructured.NestedString(cronJob.Object, "spec", "schedule")
+		schedule, err := parseCronSchedule(spec, location)
+		if err != nil {
+			anomaly := name + ": invalid schedule " + spec + " (" + err.Error() + ")"
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"CronJob", namespace, name}
+			continue
+		}
+
+		lastSchedule := cronJob.GetCreationTimestamp().Time
+		lastScheduleText := "never"
+		if last, ok, _ := unstructured.NestedString(cronJob.Object, "status", "lastScheduleTime"); ok {
+			if parsed, err := time.Parse(time.RFC3339, last); err == nil {
+				lastSchedule, lastScheduleText = parsed, last
+			}
+		}
+		grace := cronJobScheduleGrace
+		if deadline, ok, _ := unstructured.NestedInt64(cronJob.Object, "spec", "startingDeadlineSeconds"); ok {
+			grace = time.Duration(deadline) * time.Second
+		}
+		if expected := latestScheduledRun(schedule, lastSchedule, currentTime); !expected.IsZero() && currentTime.Sub(expected) > grace {
+			anomaly := name + ": missed scheduled run at " + expected.In(location).Format(time.RFC3339) +
+				", last scheduled " + lastScheduleText
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"CronJob", namespace, name}
+		}
+
+		policy, _, _ := unstructured.NestedString(cronJob.Object, "spec", "concurrencyPolicy")
+		active, _, _ := unstructured.NestedSlice(cronJob.Object, "status", "active")
+		if (policy == "" || policy == string(batchv1beta1.AllowConcurrent)) && len(active) > 1 {
+			anomaly := fmt.Sprintf("%s: %d overlapping active runs with concurrencyPolicy Allow", name, len(active))
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"CronJob", namespace, name}
+		}
+	}
+	return NewTriage("CronJobs", "Found cronjobs with invalid, missed or overlapping schedules in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// latestScheduledRun returns the most recent time the schedule fired after since and not after now,
+// or the zero time if it did not fire in between
+func latestScheduledRun(schedule *cronSchedule, since, now time.Time) time.Time {
+	first := schedule.next(since)
+	if first.IsZero() || first.After(now) {
+		return time.Time{}
+	}
+	// walk forward from the smallest window that contains a run to keep the number of steps low
+	for _, window := range []time.Duration{time.Hour, 24 * time.Hour, 32 * 24 * time.Hour, 367 * 24 * time.Hour} {
+		start := now.Add(-window)
+		if start.Before(since) {
+			start = since
+		}
+		latest := schedule.next(start)
+		if latest.IsZero() || latest.After(now) {
+			continue
+		}
+		for next := schedule.next(latest); !next.IsZero() && !next.After(now); next = schedule.next(next) {
+			latest = next
+		}
+		return latest
+	}
+	return first
+}
+
+// jobCondition returns the condition of the given type of a job when it is True
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for idx, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[idx]
+		}
+	}
+	return nil
+}
 
-	jobs, err := kubeCli.BatchV1beta1().CronJobs(namespace).List(v1.ListOptions{})
+// TriageJobs gets a kubernetes.Clientset, a specific namespace string and a duration
+// then proceeds to search jobs that failed (backoffLimit or activeDeadlineSeconds exceeded), jobs still running
+// past their activeDeadlineSeconds or the duration, and completed jobs without ttlSecondsAfterFinished that
+// nothing cleans up, finished for longer than the duration
+func TriageJobs(kubeCli *kubernetes.Clientset, namespace string, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	jobs, err := kubeCli.BatchV1().Jobs(namespace).List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
 			return nil, err
@@ -19,13 +161,92 @@ func LeftoverJobs(kubeCli *kubernetes.Clientset, namespace string) (*Triage, err
 	}
 
 	currentTime := time.Now()
-	for _, i := range jobs.Items {
-		if i.Status.LastScheduleTime != nil {
-			if currentTime.Sub(i.Status.LastScheduleTime.Local()).Hours()/24 > 30 {
-				listOfTriages = append(listOfTriages, i.GetName())
+	for idx, i := range jobs.Items {
+		job := &jobs.Items[idx]
+		if failed := jobCondition(job, batchv1.JobFailed); failed != nil {
+			anomaly := i.GetName() + ": failed " + failed.Reason + " (" + failed.Message + ")"
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Job", namespace, i.GetName()}
+			continue
+		}
+
+		if complete := jobCondition(job, batchv1.JobComplete); complete != nil {
+			// jobs of cronjobs are cleaned up by the history limits of the cronjob
+			if i.Spec.TTLSecondsAfterFinished == nil && v1.GetControllerOf(job) == nil &&
+				i.Status.CompletionTime != nil && currentTime.Sub(i.Status.CompletionTime.Time) > threshold {
+				anomaly := i.GetName() + ": completed " +
+					currentTime.Sub(i.Status.CompletionTime.Time).Round(time.Minute).String() + " ago without ttlSecondsAfterFinished"
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"Job", namespace, i.GetName()}
 			}
+			continue
+		}
+
+		if i.Status.StartTime == nil {
+			continue
+		}
+		runningFor := currentTime.Sub(i.Status.StartTime.Time)
+		if deadline := i.Spec.ActiveDeadlineSeconds; deadline != nil && runningFor > time.Duration(*deadline)*time.Second {
+			anomaly := fmt.Sprintf("%s: running for %s past activeDeadlineSeconds %d",
+				i.GetName(), runningFor.Round(time.Minute).String(), *deadline)
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Job", namespace, i.GetName()}
+		} else if runningFor > threshold {
+			anomaly := i.GetName() + ": running for " + runningFor.Round(time.Minute).String()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Job", namespace, i.GetName()}
+		}
+	}
+	return NewTriage("Jobs", "Found failed, stuck or never cleaned jobs in namespace: "+namespace, listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageCronJobs gets a kubernetes.Clientset, a dynamic client and a specific namespace string
+// then proceeds to search cronjobs that are suspended or whose most recent job failed.
+// Cronjobs that never ran are reported by LeftoverJobs as missing their scheduled run
+func TriageCronJobs(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	cronJobs, err := listCronJobs(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(cronJobs.Items) == 0 {
+		return NewTriage("CronJobs", "Found suspended or failing cronjobs in namespace: "+namespace, listOfTriages), nil
+	}
+	jobs, err := kubeCli.BatchV1().Jobs(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
 		}
+	}
+
+	// cronjob uid -> most recently started job
+	latestJobs := make(map[string]*batchv1.Job)
+	for idx := range jobs.Items {
+		job := &jobs.Items[idx]
+		owner := v1.GetControllerOf(job)
+		if owner == nil || owner.Kind != "CronJob" {
+			continue
+		}
+		if latest, ok := latestJobs[string(owner.UID)]; !ok || latest.CreationTimestamp.Before(&job.CreationTimestamp) {
+			latestJobs[string(owner.UID)] = job
+		}
+	}
 
+	for _, i := range cronJobs.Items {
+		if suspend, _, _ := unstructured.NestedBool(i.Object, "spec", "suspend"); suspend {
+			anomaly := i.GetName() + ": suspended"
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"CronJob", namespace, i.GetName()}
+			continue
+		}
+		if latest, ok := latestJobs[string(i.GetUID())]; ok {
+			if failed := jobCondition(latest, batchv1.JobFailed); failed != nil {
+				anomaly := i.GetName() + ": last run " + latest.GetName() + " failed " + failed.Reason + " (" + failed.Message + ")"
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"CronJob", namespace, i.GetName()}
+			}
+		}
 	}
-	return NewTriage("CronJobs", "Found leftover cronjobs in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("CronJobs", "Found suspended or failing cronjobs in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
 }
@@ -0,0 +1,221 @@
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const metricsGroupVersion = "metrics.k8s.io/v1beta1"
+
+// resourceMetrics mirrors the parts of metrics.k8s.io NodeMetrics/PodMetrics doctor needs,
+// decoded by hand so that doctor does not depend on the metrics client
+type resourceMetrics struct {
+	v1.ObjectMeta `json:"metadata,omitempty"`
+	Usage         corev1.ResourceList `json:"usage,omitempty"`
+	Containers    []struct {
+		Usage corev1.ResourceList `json:"usage,omitempty"`
+	} `json:"containers,omitempty"`
+}
+
+type resourceMetricsList struct {
+	Items []resourceMetrics `json:"items"`
+}
+
+// nodeUsage holds the usage of a node as a percentage of its allocatable resources
+type nodeUsage struct {
+	name    string
+	percent map[corev1.ResourceName]int64
+	node    corev1.Node
+}
+
+// metricsAvailable checks if the metrics.k8s.io API is served, which is the case only when metrics-server
+// (or an equivalent adapter) is deployed
+func metricsAvailable(kubeCli *kubernetes.Clientset) bool {
+	if _, err := kubeCli.Discovery().ServerResourcesForGroupVersion(metricsGroupVersion); err != nil {
+		log.Warn("metrics.k8s.io API is not served, skipping resource usage checks: ", err)
+		return false
+	}
+	return true
+}
+
+// fetchMetrics gets the given kind of metrics.k8s.io resource, "nodes" or "pods"
+func fetchMetrics(kubeCli *kubernetes.Clientset, kind string) (*resourceMetricsList, error) {
+	raw, err := kubeCli.CoreV1().RESTClient().Get().AbsPath("/apis", metricsGroupVersion, kind).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	list := &resourceMetricsList{}
+	if err := json.Unmarshal(raw, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// fetchNodeUsage joins node metrics with node allocatable resources
+func fetchNodeUsage(kubeCli *kubernetes.Clientset) ([]nodeUsage, error) {
+	metrics, err := fetchMetrics(kubeCli, "nodes")
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := kubeCli.CoreV1().Nodes().List(v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodesByName := make(map[string]corev1.Node)
+	for _, i := range nodes.Items {
+		nodesByName[i.GetName()] = i
+	}
+
+	usages := make([]nodeUsage, 0)
+	for _, m := range metrics.Items {
+		node, ok := nodesByName[m.GetName()]
+		if !ok {
+			continue
+		}
+		usage := nodeUsage{name: m.GetName(), percent: make(map[corev1.ResourceName]int64), node: node}
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			allocatable, ok := node.Status.Allocatable[r]
+			if !ok || allocatable.IsZero() {
+				continue
+			}
+			used := m.Usage[r]
+			usage.percent[r] = used.MilliValue() * 100 / allocatable.MilliValue()
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// NodeMetrics holds the usage of every node read once from the metrics.k8s.io API,
+// shared by the node usage and scale down triages
+type NodeMetrics struct {
+	usages []nodeUsage
+}
+
+// FetchNodeMetrics gets a kubernetes.Clientset and reads the usage of every node from the metrics.k8s.io API,
+// if metrics-server is not deployed or its metrics cannot be fetched the usage is left empty
+func FetchNodeMetrics(kubeCli *kubernetes.Clientset) *NodeMetrics {
+	if !metricsAvailable(kubeCli) {
+		return &NodeMetrics{}
+	}
+	usages, err := fetchNodeUsage(kubeCli)
+	if err != nil {
+		log.Warn("could not fetch node metrics, skipping resource usage checks: ", err)
+		return &NodeMetrics{}
+	}
+	return &NodeMetrics{usages}
+}
+
+// podsByNode lists the pods of all namespaces once and groups them as namespace/name by the node they run on
+func podsByNode(kubeCli *kubernetes.Clientset) (map[string]map[string]bool, error) {
+	pods, err := kubeCli.CoreV1().Pods("").List(v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string]map[string]bool)
+	for _, p := range pods.Items {
+		node := p.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		if byNode[node] == nil {
+			byNode[node] = make(map[string]bool)
+		}
+		byNode[node][p.GetNamespace()+"/"+p.GetName()] = true
+	}
+	return byNode, nil
+}
+
+// topPodOnNode returns the pod among the given ones that uses the most of the given resource
+func topPodOnNode(podMetrics *resourceMetricsList, onNode map[string]bool, r corev1.ResourceName) string {
+	top, topUsage := "", resource.Quantity{}
+	for _, m := range podMetrics.Items {
+		key := m.GetNamespace() + "/" + m.GetName()
+		if !onNode[key] {
+			continue
+		}
+		used := resource.Quantity{}
+		for _, c := range m.Containers {
+			used.Add(c.Usage[r])
+		}
+		if used.Cmp(topUsage) > 0 {
+			top, topUsage = key, used
+		}
+	}
+	if top == "" {
+		return ""
+	}
+	return top + " using " + topUsage.String()
+}
+
+// TriageNodeUsage gets a kubernetes.Clientset and the node metrics and checks for nodes whose
+// actual cpu or memory usage is above the given percentage of allocatable, naming their top consuming pod.
+// Without node metrics an empty triage is returned
+func TriageNodeUsage(kubeCli *kubernetes.Clientset, metrics *NodeMetrics, highWatermark int64) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	anomalyType := fmt.Sprintf("Found node/s using more than %d%% of allocatable cpu/memory!", highWatermark)
+
+	var podMetrics *resourceMetricsList
+	var onNode map[string]map[string]bool
+	for _, u := range metrics.usages {
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			percent, ok := u.percent[r]
+			if !ok || percent <= highWatermark {
+				continue
+			}
+			// pods and their metrics are only needed once a node is found above the watermark
+			if podMetrics == nil {
+				var err error
+				if podMetrics, err = fetchMetrics(kubeCli, "pods"); err != nil {
+					log.Warn("could not fetch pod metrics: ", err)
+					podMetrics = &resourceMetricsList{}
+				}
+				if onNode, err = podsByNode(kubeCli); err != nil {
+					log.Warn("could not list pods to find the top consumers: ", err)
+				}
+			}
+			anomaly := fmt.Sprintf("%s: %s usage at %d%% of allocatable", u.name, r, percent)
+			if top := topPodOnNode(podMetrics, onNode[u.name], r); top != "" {
+				anomaly += ", top consumer " + top
+			}
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Node", "", u.name}
+		}
+	}
+	return NewTriage("Nodes", anomalyType, listOfTriages).WithObjects(objects), nil
+}
+
+// TriageNodeScaleDown gets the node metrics and checks for nodes whose cpu and memory usage are both
+// below the given percentage of allocatable, which makes them scale down candidates.
+// Without node metrics an empty triage is returned
+func TriageNodeScaleDown(metrics *NodeMetrics, lowWatermark int64) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	anomalyType := fmt.Sprintf("Found node/s using less than %d%% of allocatable cpu and memory, possible scale down candidates!", lowWatermark)
+
+	usages := make([]nodeUsage, len(metrics.usages))
+	copy(usages, metrics.usages)
+	sort.Slice(usages, func(a, b int) bool { return usages[a].name < usages[b].name })
+
+	for _, u := range usages {
+		if u.node.Spec.Unschedulable {
+			continue
+		}
+		cpu, cpuOk := u.percent[corev1.ResourceCPU]
+		memory, memoryOk := u.percent[corev1.ResourceMemory]
+		if cpuOk && memoryOk && cpu < lowWatermark && memory < lowWatermark {
+			anomaly := fmt.Sprintf("%s: cpu usage at %d%%, memory usage at %d%% of allocatable", u.name, cpu, memory)
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Node", "", u.name}
+		}
+	}
+	return NewTriage("Nodes", anomalyType, listOfTriages).WithObjects(objects), nil
+}
@@ -0,0 +1,117 @@
+package triage
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TriageNetworkPolicies gets a kubernetes.Clientset, a specific namespace string and the labels of every namespace by name
+// then proceeds to search for network isolation gaps: a namespace without NetworkPolicies, pods no policy selects,
+// policies whose podSelector matches no pods and ingress/egress rules whose namespaceSelector matches no namespaces.
+// The share of pods covered by at least one policy is part of the report
+func TriageNetworkPolicies(kubeCli *kubernetes.Clientset, namespace string, namespaceLabels map[string]map[string]string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	policies, err := kubeCli.NetworkingV1().NetworkPolicies(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	pods, err := kubeCli.CoreV1().Pods(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	// pods on the host network or that are done are not subject to network policies
+	isolatable := make([]corev1.Pod, 0)
+	for _, p := range pods.Items {
+		if p.Spec.HostNetwork || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		isolatable = append(isolatable, p)
+	}
+
+	covered := make(map[string]bool)
+	for _, i := range policies.Items {
+		selector, err := v1.LabelSelectorAsSelector(&i.Spec.PodSelector)
+		if err != nil {
+			anomaly := "NetworkPolicy/" + i.GetName() + ": invalid podSelector: " + err.Error()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"NetworkPolicy", namespace, i.GetName()}
+			continue
+		}
+		matched := 0
+		for _, p := range isolatable {
+			if selector.Matches(labels.Set(p.GetLabels())) {
+				matched++
+				covered[p.GetName()] = true
+			}
+		}
+		if matched == 0 {
+			anomaly := "NetworkPolicy/" + i.GetName() + ": podSelector matches no pods"
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"NetworkPolicy", namespace, i.GetName()}
+		}
+
+		peers := make(map[string][]networkingv1.NetworkPolicyPeer)
+		for _, r := range i.Spec.Ingress {
+			peers["ingress"] = append(peers["ingress"], r.From...)
+		}
+		for _, r := range i.Spec.Egress {
+			peers["egress"] = append(peers["egress"], r.To...)
+		}
+		for _, direction := range []string{"ingress", "egress"} {
+			for _, peer := range peers[direction] {
+				if peer.NamespaceSelector == nil {
+					continue
+				}
+				nsSelector, err := v1.LabelSelectorAsSelector(peer.NamespaceSelector)
+				if err != nil {
+					continue
+				}
+				matchesAny := false
+				for _, nsLabels := range namespaceLabels {
+					if nsSelector.Matches(labels.Set(nsLabels)) {
+						matchesAny = true
+						break
+					}
+				}
+				if !matchesAny {
+					anomaly := "NetworkPolicy/" + i.GetName() + ": " + direction +
+						" namespaceSelector " + v1.FormatLabelSelector(peer.NamespaceSelector) + " matches no namespaces"
+					listOfTriages = append(listOfTriages, anomaly)
+					objects[anomaly] = ObjectRef{"NetworkPolicy", namespace, i.GetName()}
+				}
+			}
+		}
+	}
+
+	if len(policies.Items) == 0 {
+		if len(isolatable) > 0 {
+			listOfTriages = append(listOfTriages, "no network policies, all pods accept any traffic")
+		}
+	} else {
+		for _, p := range isolatable {
+			if !covered[p.GetName()] {
+				anomaly := "Pod/" + p.GetName() + ": not selected by any network policy"
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"Pod", namespace, p.GetName()}
+			}
+		}
+	}
+
+	coverage := 100
+	if len(isolatable) > 0 {
+		coverage = len(covered) * 100 / len(isolatable)
+	}
+	return NewTriage("NetworkPolicies",
+		fmt.Sprintf("Found network policy gaps in namespace: %s (%d%% of pods covered)", namespace, coverage), listOfTriages).WithObjects(objects), nil
+}
@@ -1,16 +1,34 @@
 package triage
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
-const targetReason = "KubeletReady"
+const (
+	targetReason = "KubeletReady"
+	// event the node controller records when a node is cordoned
+	eventReasonNodeNotSchedulable = "NodeNotSchedulable"
+)
+
+// node conditions that signal the node is degraded when their status is True
+var nodePressureConditions = []corev1.NodeConditionType{
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+	corev1.NodeNetworkUnavailable,
+}
 
 // TriageNodes gets a coreclient for k8s and checks if there are any nodes in the cluster
 // that are not in Ready state(unoperational nodes)
 func TriageNodes(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	nodes, err := coreClient.Nodes().List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
@@ -23,9 +41,234 @@ func TriageNodes(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 			if y.Reason == targetReason {
 				if y.Status != "True" {
 					listOfTriages = append(listOfTriages, i.GetName())
+					objects[i.GetName()] = ObjectRef{"Node", "", i.GetName()}
 				}
 			}
 		}
 	}
-	return NewTriage("Nodes", "Found node/s that are not in Ready state!", listOfTriages), nil
+	return NewTriage("Nodes", "Found node/s that are not in Ready state!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageNodeConditions gets a coreclient for k8s and checks if there are any nodes
+// reporting MemoryPressure, DiskPressure, PIDPressure or NetworkUnavailable
+func TriageNodeConditions(coreClient coreclient.CoreV1Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	nodes, err := coreClient.Nodes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	for _, i := range nodes.Items {
+		for _, y := range i.Status.Conditions {
+			for _, c := range nodePressureConditions {
+				if y.Type == c && y.Status == corev1.ConditionTrue {
+					anomaly := i.GetName() + ": " + string(y.Type) + " (" + y.Message + ")"
+					listOfTriages = append(listOfTriages, anomaly)
+					objects[anomaly] = ObjectRef{"Node", "", i.GetName()}
+				}
+			}
+		}
+	}
+	return NewTriage("Nodes", "Found node/s under resource pressure or with unavailable network!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageCordonedNodes gets a coreclient for k8s and checks if there are any nodes
+// that have been marked unschedulable for longer than the given threshold.
+// The cordon time is taken from the managedFields entry owning spec.unschedulable, or from the
+// NodeNotSchedulable event when the server does not track managed fields, otherwise the node
+// is reported with an unknown duration
+func TriageCordonedNodes(coreClient coreclient.CoreV1Interface, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	nodes, err := coreClient.Nodes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	var cordonTimes map[string]time.Time
+	currentTime := time.Now()
+	for _, i := range nodes.Items {
+		if !i.Spec.Unschedulable {
+			continue
+		}
+		if cordonTimes == nil {
+			if cordonTimes, err = nodeCordonTimes(coreClient); err != nil {
+				return nil, err
+			}
+		}
+		cordonedAt, ok := cordonTimes[i.GetName()]
+		if !ok {
+			anomaly := i.GetName() + ": cordoned for an unknown duration"
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Node", "", i.GetName()}
+			continue
+		}
+		if cordonedFor := currentTime.Sub(cordonedAt); cordonedFor > threshold {
+			anomaly := i.GetName() + ": cordoned for " + cordonedFor.Round(time.Minute).String()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Node", "", i.GetName()}
+		}
+	}
+	return NewTriage("Nodes", "Found node/s cordoned for longer than "+threshold.String()+"!", listOfTriages).WithObjects(objects), nil
+}
+
+// nodeCordonTimes returns, by node name, when spec.unschedulable was last written.
+// The nodes are read raw because the typed client predates the fieldsV1 format of managedFields.
+// A managedFields entry records the last time its manager wrote any of its fields, so the time is
+// a lower bound of how long the node has been cordoned
+func nodeCordonTimes(coreClient coreclient.CoreV1Interface) (map[string]time.Time, error) {
+	cordonTimes := make(map[string]time.Time)
+	raw, err := coreClient.RESTClient().Get().AbsPath("/api/v1/nodes").DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	nodes := struct {
+		Items []struct {
+			Metadata struct {
+				Name          string `json:"name"`
+				ManagedFields []struct {
+					Time     *v1.Time        `json:"time"`
+					Fields   json.RawMessage `json:"fields"`
+					FieldsV1 json.RawMessage `json:"fieldsV1"`
+				} `json:"managedFields"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}{}
+	if err := json.Unmarshal(raw, &nodes); err != nil {
+		return nil, err
+	}
+	for _, i := range nodes.Items {
+		for _, f := range i.Metadata.ManagedFields {
+			fields := f.FieldsV1
+			if len(fields) == 0 {
+				fields = f.Fields
+			}
+			if f.Time == nil || !managesField(fields, "f:spec", "f:unschedulable") {
+				continue
+			}
+			if t, ok := cordonTimes[i.Metadata.Name]; !ok || f.Time.After(t) {
+				cordonTimes[i.Metadata.Name] = f.Time.Time
+			}
+		}
+	}
+
+	events, err := coreClient.Events("").List(v1.ListOptions{
+		FieldSelector: "involvedObject.kind=Node,reason=" + eventReasonNodeNotSchedulable,
+	})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	fromEvents := make(map[string]time.Time)
+	for _, e := range events.Items {
+		// a node cordoned several times records the event again, its last occurrence is the current cordon
+		at := e.LastTimestamp.Time
+		if at.IsZero() {
+			at = e.EventTime.Time
+		}
+		if name := e.InvolvedObject.Name; at.After(fromEvents[name]) {
+			fromEvents[name] = at
+		}
+	}
+	for name, at := range fromEvents {
+		if _, ok := cordonTimes[name]; !ok {
+			cordonTimes[name] = at
+		}
+	}
+	return cordonTimes, nil
+}
+
+// managesField reports whether a managedFields field set contains the given path
+func managesField(fields json.RawMessage, path ...string) bool {
+	for _, key := range path {
+		set := make(map[string]json.RawMessage)
+		if json.Unmarshal(fields, &set) != nil {
+			return false
+		}
+		if fields = set[key]; fields == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// TriageNoExecuteTaints gets a coreclient for k8s and checks if there are any nodes
+// carrying NoExecute taints, which evict every pod that does not tolerate them
+func TriageNoExecuteTaints(coreClient coreclient.CoreV1Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	nodes, err := coreClient.Nodes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	for _, i := range nodes.Items {
+		for _, t := range i.Spec.Taints {
+			if t.Effect == corev1.TaintEffectNoExecute {
+				anomaly := i.GetName() + ": " + t.ToString()
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"Node", "", i.GetName()}
+			}
+		}
+	}
+	return NewTriage("Nodes", "Found node/s with NoExecute taints!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageNodeOvercommit gets a coreclient for k8s and checks if the sum of cpu/memory requests
+// of the pods scheduled on a node exceeds the given percentage of the node's allocatable resources
+func TriageNodeOvercommit(coreClient coreclient.CoreV1Interface, thresholdPercent int64) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	nodes, err := coreClient.Nodes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	pods, err := coreClient.Pods("").List(v1.ListOptions{
+		FieldSelector: "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed),
+	})
+	if err != nil {
+		return nil, err
+	}
+	requestedByNode := make(map[string]corev1.ResourceList)
+	for _, p := range pods.Items {
+		node := p.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		if requestedByNode[node] == nil {
+			requestedByNode[node] = corev1.ResourceList{}
+		}
+		addResourceList(requestedByNode[node], podRequests(&p.Spec))
+	}
+
+	for _, i := range nodes.Items {
+		requested := requestedByNode[i.GetName()]
+
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			allocatable, ok := i.Status.Allocatable[r]
+			if !ok || allocatable.IsZero() {
+				continue
+			}
+			req := requested[r]
+			percent := req.MilliValue() * 100 / allocatable.MilliValue()
+			if percent > thresholdPercent {
+				anomaly := fmt.Sprintf("%s: %s requests %s of %s allocatable (%d%%)",
+					i.GetName(), r, req.String(), allocatable.String(), percent)
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"Node", "", i.GetName()}
+			}
+		}
+	}
+	return NewTriage("Nodes", fmt.Sprintf("Found node/s with requests above %d%% of allocatable!", thresholdPercent), listOfTriages).WithObjects(objects), nil
 }
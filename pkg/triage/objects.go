@@ -0,0 +1,128 @@
+package triage
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// number of objects requested per page while walking the cluster
+const objectPageSize = 500
+
+// resources that are never worth walking object by object, they are high volume and short lived
+var skippedResources = map[string]bool{
+	"events": true,
+}
+
+// objectCheck is a triage computed from every object of the cluster, fed one object at a time
+type objectCheck interface {
+	visit(resource v1.APIResource, object *unstructured.Unstructured)
+	triage() (*Triage, error)
+}
+
+// ObjectWalk holds what a single walk over every object of the cluster found: the triages of
+// objects stuck in Terminating, dangling owner references and deprecated API versions, in that order,
+// and the ownerReferences of all objects, used to attach evidence and correlate findings
+type ObjectWalk struct {
+	Triages []*Triage
+	owners  *ownerGraph
+}
+
+// WalkObjects gets a kubernetes.Clientset, a dynamic client, the terminating threshold and the deprecated
+// api window then lists every object of the cluster, custom resources included, once and hands each one
+// to the terminating, owner reference and deprecated api checks
+func WalkObjects(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, terminatingThreshold time.Duration, deprecatedAPIWindow int) (*ObjectWalk, error) {
+	deprecatedAPIs, err := newDeprecatedAPIsCheck(kubeCli, deprecatedAPIWindow)
+	if err != nil {
+		return nil, err
+	}
+	danglingOwners := newDanglingOwnersCheck()
+	checks := []objectCheck{newTerminatingCheck(terminatingThreshold), danglingOwners, deprecatedAPIs}
+
+	listedKinds, err := forEachObject(kubeCli, dynamicCli, func(resource v1.APIResource, object *unstructured.Unstructured) {
+		for _, c := range checks {
+			c.visit(resource, object)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	danglingOwners.listedKinds = listedKinds
+
+	walk := &ObjectWalk{Triages: make([]*Triage, 0, len(checks)), owners: danglingOwners.graph()}
+	for _, c := range checks {
+		t, err := c.triage()
+		if err != nil {
+			return nil, err
+		}
+		walk.Triages = append(walk.Triages, t)
+	}
+	return walk, nil
+}
+
+// forEachObject discovers every listable resource the server serves, including custom resources,
+// lists all of their objects across namespaces page by page with the dynamic client and calls fn on each one.
+// Resources served by several groups (ingresses in extensions and networking.k8s.io, ...) return the
+// same objects, fn is called only once per uid, for the first group listing it.
+// Groups that fail discovery and resources that cannot be listed are logged and skipped, the kinds
+// returned are those whose objects were all listed, under every group serving them
+func forEachObject(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface,
+	fn func(resource v1.APIResource, object *unstructured.Unstructured)) (map[schema.GroupKind]bool, error) {
+	resourceLists, err := kubeCli.Discovery().ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, err
+		}
+		log.Warn("some api groups could not be discovered and are skipped: ", err)
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list"}}, resourceLists)
+	seen := make(map[types.UID]bool)
+	listedKinds := make(map[schema.GroupKind]bool)
+
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if skippedResources[r.Name] {
+				continue
+			}
+			r.Group, r.Version = gv.Group, gv.Version
+			opts := v1.ListOptions{Limit: objectPageSize}
+			for {
+				objects, err := dynamicCli.Resource(gv.WithResource(r.Name)).List(opts)
+				if err != nil {
+					log.Warn("could not list "+r.Name+"."+gv.String()+": ", err)
+					break
+				}
+				for idx := range objects.Items {
+					if uid := objects.Items[idx].GetUID(); uid != "" {
+						if seen[uid] {
+							continue
+						}
+						seen[uid] = true
+					}
+					fn(r, &objects.Items[idx])
+				}
+				if opts.Continue = objects.GetContinue(); opts.Continue == "" {
+					listedKinds[schema.GroupKind{Group: gv.Group, Kind: r.Kind}] = true
+					break
+				}
+			}
+		}
+	}
+	return listedKinds, nil
+}
+
+// objectKey formats an object as kind namespace/name, or kind name for cluster scoped objects
+func objectKey(kind string, object v1.Object) string {
+	return ObjectRef{kind, object.GetNamespace(), object.GetName()}.String()
+}
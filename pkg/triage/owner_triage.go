@@ -0,0 +1,87 @@
+package triage
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ownedObject is an object with at least one ownerReference, kept until every owner uid is known
+type ownedObject struct {
+	ref      ObjectRef
+	owners   []v1.OwnerReference
+	deleting bool
+}
+
+// danglingOwnersCheck walks the ownerReferences of every object in the cluster, custom resources included,
+// and reports the objects whose owner uid no longer exists, which the garbage collector should have cleaned up,
+// or whose namespaced owner lives in another namespace.
+// Owners of kinds that could not be fully listed are not reported since their uids are unknown,
+// listedKinds is filled in once the walk is over
+type danglingOwnersCheck struct {
+	uids        map[types.UID]ObjectRef
+	listedKinds map[schema.GroupKind]bool
+	owned       []ownedObject
+}
+
+func newDanglingOwnersCheck() *danglingOwnersCheck {
+	return &danglingOwnersCheck{
+		uids:        make(map[types.UID]ObjectRef),
+		listedKinds: make(map[schema.GroupKind]bool),
+		owned:       make([]ownedObject, 0),
+	}
+}
+
+func (c *danglingOwnersCheck) visit(resource v1.APIResource, object *unstructured.Unstructured) {
+	ref := ObjectRef{resource.Kind, object.GetNamespace(), object.GetName()}
+	c.uids[object.GetUID()] = ref
+	if owners := object.GetOwnerReferences(); len(owners) > 0 {
+		c.owned = append(c.owned, ownedObject{ref, owners, object.GetDeletionTimestamp() != nil})
+	}
+}
+
+func (c *danglingOwnersCheck) triage() (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	for _, i := range c.owned {
+		// objects already being deleted are left to the garbage collector and the terminating triage
+		if i.deleting {
+			continue
+		}
+		for _, o := range i.owners {
+			gv, err := schema.ParseGroupVersion(o.APIVersion)
+			if err != nil || !c.listedKinds[gv.WithKind(o.Kind).GroupKind()] {
+				continue
+			}
+			owner, ok := c.uids[o.UID]
+			var anomaly string
+			if !ok {
+				anomaly = i.ref.String() + ": owner " + o.Kind + " " + o.Name + " (uid " + string(o.UID) + ") no longer exists"
+			} else if owner.Namespace != "" && owner.Namespace != i.ref.Namespace {
+				anomaly = i.ref.String() + ": owner " + owner.String() + " is in another namespace, cross-namespace owner references are not honored"
+			} else {
+				continue
+			}
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = i.ref
+		}
+	}
+	return NewTriage("OwnerReferences", "Found objects whose owner no longer exists!", listOfTriages).WithObjects(objects), nil
+}
+
+// graph links every walked object to its owners, resolved by uid so cluster scoped owners
+// (the Node of a mirror pod, ...) keep their empty namespace
+func (c *danglingOwnersCheck) graph() *ownerGraph {
+	g := newOwnerGraph()
+	for _, i := range c.owned {
+		for _, o := range i.owners {
+			owner, ok := c.uids[o.UID]
+			if !ok {
+				owner = ObjectRef{o.Kind, i.ref.Namespace, o.Name}
+			}
+			g.link(owner, i.ref)
+		}
+	}
+	return g
+}
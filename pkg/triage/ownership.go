@@ -0,0 +1,39 @@
+package triage
+
+// ownerGraph links objects to the objects they own through their ownerReferences
+type ownerGraph struct {
+	children map[ObjectRef][]ObjectRef
+	owners   map[ObjectRef][]ObjectRef
+}
+
+func newOwnerGraph() *ownerGraph {
+	return &ownerGraph{
+		children: make(map[ObjectRef][]ObjectRef),
+		owners:   make(map[ObjectRef][]ObjectRef),
+	}
+}
+
+func (g *ownerGraph) link(owner, child ObjectRef) {
+	g.children[owner] = append(g.children[owner], child)
+	g.owners[child] = append(g.owners[child], owner)
+}
+
+// descendants returns every object owned by ref directly or through intermediate owners
+func (g *ownerGraph) descendants(ref ObjectRef) []ObjectRef {
+	result := make([]ObjectRef, 0)
+	seen := map[ObjectRef]bool{ref: true}
+	queue := []ObjectRef{ref}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range g.children[current] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	pdbsV1      = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
+	pdbsV1beta1 = schema.GroupVersionResource{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"}
+)
+
+// listPDBs gets a dynamic client and a specific namespace string then lists the PodDisruptionBudgets in it
+// from policy/v1, falling back to policy/v1beta1 on servers that predate it. The v1beta1 type covers every field
+// doctor reads, an empty selector is turned into nil for v1beta1 where it selects no pods instead of all of them
+func listPDBs(dynamicCli dynamic.Interface, namespace string) ([]policyv1beta1.PodDisruptionBudget, error) {
+	pdbs := make([]policyv1beta1.PodDisruptionBudget, 0)
+	beta := false
+	list, err := dynamicCli.Resource(pdbsV1).Namespace(namespace).List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		beta = true
+		list, err = dynamicCli.Resource(pdbsV1beta1).Namespace(namespace).List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return pdbs, nil
+	}
+	for _, i := range list.Items {
+		pdb := policyv1beta1.PodDisruptionBudget{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &pdb); err != nil {
+			return nil, err
+		}
+		if s := pdb.Spec.Selector; beta && s != nil && len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0 {
+			pdb.Spec.Selector = nil
+		}
+		pdbs = append(pdbs, pdb)
+	}
+	return pdbs, nil
+}
+
+// TriagePDB gets a kubernetes.Clientset, a dynamic client and a specific namespace string
+// then proceeds to search PodDisruptionBudgets that select no pods, that allow no disruption although
+// their pods are healthy (which blocks node drains), that are configured with maxUnavailable=0 or
+// minAvailable equal to the number of pods, and that overlap with another PDB on the same pods
+func TriagePDB(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	pdbs, err := listPDBs(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(pdbs) == 0 {
+		return NewTriage("PodDisruptionBudgets", "Found PDBs that select nothing or block drains in namespace: "+namespace, listOfTriages), nil
+	}
+	pods, err := kubeCli.CoreV1().Pods(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	pdbsByPod := make(map[string][]string)
+	for _, i := range pdbs {
+		issues := make([]string, 0)
+		selector, err := v1.LabelSelectorAsSelector(i.Spec.Selector)
+		if err != nil {
+			issues = append(issues, "invalid selector: "+err.Error())
+			selector = labels.Nothing()
+		}
+
+		matched := 0
+		for _, p := range pods.Items {
+			if selector.Matches(labels.Set(p.GetLabels())) {
+				matched++
+				pdbsByPod[p.GetName()] = append(pdbsByPod[p.GetName()], i.GetName())
+			}
+		}
+		if matched == 0 {
+			issues = append(issues, "selector matches no pods")
+		}
+
+		if maxUnavailable := i.Spec.MaxUnavailable; maxUnavailable != nil && isZeroIntOrPercent(*maxUnavailable) {
+			issues = append(issues, "maxUnavailable is 0")
+		}
+		if minAvailable := i.Spec.MinAvailable; minAvailable != nil && matched > 0 &&
+			((minAvailable.Type == intstr.Int && int(minAvailable.IntVal) >= matched) ||
+				(minAvailable.Type == intstr.String && minAvailable.StrVal == "100%")) {
+			issues = append(issues, "minAvailable "+minAvailable.String()+" leaves no room for disruption")
+		}
+		if i.Status.ExpectedPods > 0 && i.Status.CurrentHealthy >= i.Status.ExpectedPods && i.Status.PodDisruptionsAllowed == 0 {
+			issues = append(issues, "disruptionsAllowed is 0 while all pods are healthy, node drains will block")
+		}
+		if len(issues) > 0 {
+			anomaly := i.GetName() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"PodDisruptionBudget", namespace, i.GetName()}
+		}
+	}
+
+	overlaps := make(map[string]bool)
+	for _, names := range pdbsByPod {
+		if len(names) > 1 {
+			sort.Strings(names)
+			overlaps[strings.Join(names, ", ")] = true
+		}
+	}
+	overlapping := make([]string, 0, len(overlaps))
+	for names := range overlaps {
+		overlapping = append(overlapping, names)
+	}
+	sort.Strings(overlapping)
+	for _, names := range overlapping {
+		listOfTriages = append(listOfTriages, names+": select the same pods")
+	}
+	return NewTriage("PodDisruptionBudgets", "Found PDBs that select nothing or block drains in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// isZeroIntOrPercent reports whether an int-or-percent value is 0 or 0%
+func isZeroIntOrPercent(value intstr.IntOrString) bool {
+	if value.Type == intstr.Int {
+		return value.IntVal == 0
+	}
+	return value.StrVal == "0" || value.StrVal == "0%"
+}
@@ -0,0 +1,282 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	// seccomp annotations predating the securityContext.seccompProfile field
+	seccompPodAnnotation             = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	seccompUnconfined                = "Unconfined"
+	capabilityAll                    = "ALL"
+	capabilityNetBindService         = "NET_BIND_SERVICE"
+)
+
+// capabilities the baseline profile allows to be added
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true, "KILL": true, "MKNOD": true,
+	"NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// volume sources the restricted profile allows, hostPath is already a baseline violation
+var restrictedVolumeSources = map[string]bool{
+	"configMap": true, "csi": true, "downwardAPI": true, "emptyDir": true, "ephemeral": true,
+	"persistentVolumeClaim": true, "projected": true, "secret": true,
+}
+
+// workload kinds by their apps/v1 resource, used to read fields the typed client of doctor does not know
+var workloadResources = map[string]string{
+	"Deployment":  "deployments",
+	"StatefulSet": "statefulsets",
+	"DaemonSet":   "daemonsets",
+}
+
+// TriagePodSecurity gets a kubernetes.Clientset, a dynamic client and a specific namespace string
+// then proceeds to evaluate the pod template of every workload against the Pod Security Standards
+// baseline and restricted profiles, reporting the violated controls per workload.
+// The pod-security.kubernetes.io/enforce label of the namespace is part of the report
+func TriagePodSecurity(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	enforce := "none"
+	ns, err := kubeCli.CoreV1().Namespaces().Get(namespace, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if level, ok := ns.GetLabels()[podSecurityEnforceLabel]; ok {
+		enforce = level
+	}
+	anomalyType := "Found workloads violating Pod Security Standards in namespace: " + namespace + " (enforce: " + enforce + ")"
+	if len(workloads) == 0 {
+		return NewTriage("PodSecurity", anomalyType, listOfTriages), nil
+	}
+
+	fields, err := unstructuredTemplateFields(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range workloads {
+		baseline, restricted := podSecurityViolations(&w.Template, fields[w.String()])
+		if len(baseline) == 0 && len(restricted) == 0 {
+			continue
+		}
+		parts := make([]string, 0)
+		if len(baseline) > 0 {
+			parts = append(parts, "baseline ["+strings.Join(baseline, ", ")+"]")
+		}
+		if len(restricted) > 0 {
+			parts = append(parts, "restricted ["+strings.Join(restricted, ", ")+"]")
+		}
+		anomaly := w.String() + ": " + strings.Join(parts, ", ")
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = w.ref()
+	}
+	return NewTriage("PodSecurity", anomalyType, listOfTriages).WithObjects(objects), nil
+}
+
+// templateFields holds the parts of a pod template the typed client predates
+type templateFields struct {
+	// securityContext.seccompProfile.type by container name, the empty container name holding the pod level profile
+	seccompProfiles map[string]string
+	// volume name -> key of its volume source (emptyDir, ephemeral, ...)
+	volumeSources map[string]string
+}
+
+// unstructuredTemplateFields reads the seccomp profile types and volume source kinds of the pod templates
+// of the workloads in a namespace from their unstructured objects, keyed by workload
+func unstructuredTemplateFields(dynamicCli dynamic.Interface, namespace string) (map[string]templateFields, error) {
+	fields := make(map[string]templateFields)
+	for kind, resource := range workloadResources {
+		objects, err := dynamicCli.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: resource}).
+			Namespace(namespace).List(v1.ListOptions{})
+		if err != nil {
+			if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+				return nil, err
+			}
+			continue
+		}
+		for _, o := range objects.Items {
+			workloadProfiles := make(map[string]string)
+			if t, ok, _ := unstructured.NestedString(o.Object, "spec", "template", "spec", "securityContext", "seccompProfile", "type"); ok {
+				workloadProfiles[""] = t
+			}
+			for _, field := range []string{"initContainers", "containers"} {
+				containers, _, _ := unstructured.NestedSlice(o.Object, "spec", "template", "spec", field)
+				for _, c := range containers {
+					container, ok := c.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					name, _, _ := unstructured.NestedString(container, "name")
+					if t, ok, _ := unstructured.NestedString(container, "securityContext", "seccompProfile", "type"); ok {
+						workloadProfiles[name] = t
+					}
+				}
+			}
+			volumeSources := make(map[string]string)
+			volumes, _, _ := unstructured.NestedSlice(o.Object, "spec", "template", "spec", "volumes")
+			for _, v := range volumes {
+				volume, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _, _ := unstructured.NestedString(volume, "name")
+				for key := range volume {
+					if key != "name" {
+						volumeSources[name] = key
+					}
+				}
+			}
+			fields[kind+"/"+o.GetName()] = templateFields{workloadProfiles, volumeSources}
+		}
+	}
+	return fields, nil
+}
+
+// seccompFromAnnotation maps a legacy seccomp annotation value to a seccompProfile type
+func seccompFromAnnotation(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case value == "unconfined":
+		return seccompUnconfined
+	case strings.HasPrefix(value, "localhost/"):
+		return "Localhost"
+	default:
+		return "RuntimeDefault"
+	}
+}
+
+// podSecurityViolations evaluates a pod template against the baseline and restricted profiles,
+// restricted only lists the controls that baseline does not already cover
+func podSecurityViolations(template *corev1.PodTemplateSpec, fields templateFields) (baseline, restricted []string) {
+	baselineSet, restrictedSet := make(map[string]bool), make(map[string]bool)
+	spec := &template.Spec
+
+	if spec.HostNetwork {
+		baselineSet["hostNetwork"] = true
+	}
+	if spec.HostPID {
+		baselineSet["hostPID"] = true
+	}
+	if spec.HostIPC {
+		baselineSet["hostIPC"] = true
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			baselineSet["hostPath volume "+v.Name] = true
+		}
+		// generic ephemeral volumes decode to an empty source in the typed client, the unstructured key tells them apart
+		if source, ok := fields.volumeSources[v.Name]; ok {
+			if !restrictedVolumeSources[source] && source != "hostPath" {
+				restrictedSet["volume type of "+v.Name] = true
+			}
+		} else if v.ConfigMap == nil && v.CSI == nil && v.DownwardAPI == nil && v.EmptyDir == nil &&
+			v.PersistentVolumeClaim == nil && v.Projected == nil && v.Secret == nil && v.HostPath == nil {
+			restrictedSet["volume type of "+v.Name] = true
+		}
+	}
+
+	podContext := spec.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+	podSeccomp := fields.seccompProfiles[""]
+	if podSeccomp == "" {
+		podSeccomp = seccompFromAnnotation(template.Annotations[seccompPodAnnotation])
+	}
+
+	for _, c := range podContainers(spec) {
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			baselineSet["privileged ("+c.Name+")"] = true
+		}
+		for _, p := range c.Ports {
+			if p.HostPort != 0 {
+				baselineSet["hostPort ("+c.Name+")"] = true
+			}
+		}
+		if sc.ProcMount != nil && *sc.ProcMount == corev1.UnmaskedProcMount {
+			baselineSet["procMount ("+c.Name+")"] = true
+		}
+
+		dropsAll := false
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !baselineCapabilities[capability] {
+					baselineSet["capability "+string(capability)+" ("+c.Name+")"] = true
+				} else if capability != capabilityNetBindService {
+					restrictedSet["capability "+string(capability)+" ("+c.Name+")"] = true
+				}
+			}
+			for _, capability := range sc.Capabilities.Drop {
+				if capability == capabilityAll {
+					dropsAll = true
+				}
+			}
+		}
+		if !dropsAll {
+			restrictedSet["capabilities not dropping ALL ("+c.Name+")"] = true
+		}
+
+		seccomp := fields.seccompProfiles[c.Name]
+		if seccomp == "" {
+			seccomp = seccompFromAnnotation(template.Annotations[seccompContainerAnnotationPrefix+c.Name])
+		}
+		if seccomp == "" {
+			seccomp = podSeccomp
+		}
+		if seccomp == seccompUnconfined {
+			baselineSet["seccomp Unconfined ("+c.Name+")"] = true
+		} else if seccomp == "" {
+			restrictedSet["seccomp not set ("+c.Name+")"] = true
+		}
+
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			restrictedSet["allowPrivilegeEscalation ("+c.Name+")"] = true
+		}
+		runAsNonRoot := podContext.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			restrictedSet["runAsNonRoot ("+c.Name+")"] = true
+		}
+		runAsUser := podContext.RunAsUser
+		if sc.RunAsUser != nil {
+			runAsUser = sc.RunAsUser
+		}
+		if runAsUser != nil && *runAsUser == 0 {
+			restrictedSet["runAsUser 0 ("+c.Name+")"] = true
+		}
+	}
+
+	for control := range baselineSet {
+		baseline = append(baseline, control)
+	}
+	for control := range restrictedSet {
+		restricted = append(restricted, control)
+	}
+	sort.Strings(baseline)
+	sort.Strings(restricted)
+	return baseline, restricted
+}
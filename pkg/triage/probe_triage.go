@@ -0,0 +1,123 @@
+package triage
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// probes waiting longer than this before their first check are likely hiding a slow start
+	maxProbeInitialDelaySeconds = 300
+	// kubelet defaults applied when the fields are left empty
+	defaultProbePeriodSeconds  = 10
+	defaultProbeTimeoutSeconds = 1
+)
+
+// TriageProbes gets a kubernetes.Clientset and a specific namespace string
+// then proceeds to search workloads with missing or misconfigured liveness/readiness probes:
+// no readiness probe while selected by a Service, liveness probe identical to the readiness probe,
+// probes pointing at ports the container does not declare and suspicious delay/timeout values
+func TriageProbes(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	services, err := kubeCli.CoreV1().Services(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	for _, w := range workloads {
+		selected := false
+		for _, s := range services.Items {
+			if len(s.Spec.Selector) > 0 && labels.SelectorFromSet(s.Spec.Selector).Matches(labels.Set(w.Template.Labels)) {
+				selected = true
+				break
+			}
+		}
+
+		issues := make([]string, 0)
+		for _, c := range w.Template.Spec.Containers {
+			if selected && c.ReadinessProbe == nil {
+				issues = append(issues, c.Name+" has no readiness probe but is selected by a service")
+			}
+			if c.LivenessProbe != nil && c.ReadinessProbe != nil && equality.Semantic.DeepEqual(c.LivenessProbe, c.ReadinessProbe) {
+				issues = append(issues, c.Name+" liveness probe is identical to its readiness probe")
+			}
+			issues = append(issues, probeIssues(c, "liveness", c.LivenessProbe)...)
+			issues = append(issues, probeIssues(c, "readiness", c.ReadinessProbe)...)
+		}
+		if len(issues) > 0 {
+			anomaly := w.String() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = w.ref()
+		}
+	}
+	return NewTriage("Workloads", "Found workloads with missing or misconfigured probes in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// probeIssues checks a single probe of a container for undeclared ports and suspicious timings
+func probeIssues(c corev1.Container, kind string, probe *corev1.Probe) []string {
+	issues := make([]string, 0)
+	if probe == nil {
+		return issues
+	}
+
+	var port *intstr.IntOrString
+	if probe.HTTPGet != nil {
+		port = &probe.HTTPGet.Port
+	} else if probe.TCPSocket != nil {
+		port = &probe.TCPSocket.Port
+	}
+	if port != nil && !containerDeclaresPort(c, *port) {
+		issues = append(issues, fmt.Sprintf("%s %s probe targets undeclared port %s", c.Name, kind, port.String()))
+	}
+
+	if probe.InitialDelaySeconds > maxProbeInitialDelaySeconds {
+		issues = append(issues, fmt.Sprintf("%s %s probe initialDelaySeconds is %d", c.Name, kind, probe.InitialDelaySeconds))
+	}
+	period, timeout := probe.PeriodSeconds, probe.TimeoutSeconds
+	if period == 0 {
+		period = defaultProbePeriodSeconds
+	}
+	if timeout == 0 {
+		timeout = defaultProbeTimeoutSeconds
+	}
+	if timeout >= period {
+		issues = append(issues, fmt.Sprintf("%s %s probe timeoutSeconds %d is not lower than periodSeconds %d", c.Name, kind, timeout, period))
+	}
+	return issues
+}
+
+// containerDeclaresPort checks a probe port against the ports of a container
+// named ports must always be declared, numeric ports are only checked when the container declares any port
+func containerDeclaresPort(c corev1.Container, port intstr.IntOrString) bool {
+	if port.Type == intstr.String {
+		for _, p := range c.Ports {
+			if p.Name == port.StrVal {
+				return true
+			}
+		}
+		return false
+	}
+	if len(c.Ports) == 0 {
+		return true
+	}
+	for _, p := range c.Ports {
+		if p.ContainerPort == port.IntVal {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,12 @@
 package triage
 
 import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
@@ -10,6 +15,7 @@ const pvAvailable = "Available"
 // TriagePV gets a coreclient and checks if there are any pvs that are Available and Unclaimed
 func TriagePV(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	pvs, err := coreClient.PersistentVolumes().List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
@@ -20,7 +26,81 @@ func TriagePV(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 	for _, i := range pvs.Items {
 		if i.Status.Phase == pvAvailable {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"PersistentVolume", "", i.GetName()}
+		}
+	}
+	return NewTriage("PV", "Found PV in Available & Unclaimed State!", listOfTriages).WithObjects(objects), nil
+}
+
+// TriageReleasedPV gets a coreclient and checks if there are any pvs that are Released, whose claim
+// was deleted but whose data was retained, or Failed, whose automatic reclamation failed
+func TriageReleasedPV(coreClient coreclient.CoreV1Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	pvs, err := coreClient.PersistentVolumes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	for _, i := range pvs.Items {
+		if i.Status.Phase == corev1.VolumeReleased || i.Status.Phase == corev1.VolumeFailed {
+			anomaly := i.GetName() + ": " + string(i.Status.Phase)
+			if i.Status.Message != "" {
+				anomaly += " (" + i.Status.Message + ")"
+			}
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"PersistentVolume", "", i.GetName()}
+		}
+	}
+	return NewTriage("PV", "Found PV in Released or Failed State!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageStatefulPVReclaimPolicy gets a kubernetes.Clientset and checks if there are any pvs
+// with the Delete reclaim policy bound to claims of a StatefulSet volumeClaimTemplate,
+// deleting such a claim also deletes the data of the StatefulSet replica
+func TriageStatefulPVReclaimPolicy(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	statefulSets, err := kubeCli.AppsV1().StatefulSets("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	if len(statefulSets.Items) == 0 {
+		return NewTriage("PV", "Found PV with Delete reclaim policy backing StatefulSet data!", listOfTriages), nil
+	}
+	pvs, err := kubeCli.CoreV1().PersistentVolumes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	for _, i := range pvs.Items {
+		claim := i.Spec.ClaimRef
+		if claim == nil || i.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+			continue
+		}
+		for _, s := range statefulSets.Items {
+			if s.GetNamespace() != claim.Namespace {
+				continue
+			}
+			for _, t := range s.Spec.VolumeClaimTemplates {
+				// claims are named <template>-<statefulset>-<ordinal>
+				ordinal := strings.TrimPrefix(claim.Name, t.GetName()+"-"+s.GetName()+"-")
+				if ordinal == claim.Name {
+					continue
+				}
+				if _, err := strconv.Atoi(ordinal); err == nil {
+					anomaly := i.GetName() + ": bound to " + claim.Namespace + "/" + claim.Name + " of StatefulSet/" + s.GetName()
+					listOfTriages = append(listOfTriages, anomaly)
+					objects[anomaly] = ObjectRef{"PersistentVolume", "", i.GetName()}
+				}
+			}
 		}
 	}
-	return NewTriage("PV", "Found PV in Available & Unclaimed State!", listOfTriages), nil
+	return NewTriage("PV", "Found PV with Delete reclaim policy backing StatefulSet data!", listOfTriages).WithObjects(objects), nil
 }
@@ -1,15 +1,27 @@
 package triage
 
 import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
-const pvcLostPhase = "Lost"
+const (
+	pvcLostPhase = "Lost"
+	// event reason the persistent volume controller and external provisioners use when provisioning fails
+	pvcProvisioningFailed = "ProvisioningFailed"
+	// annotation the scheduler sets on a WaitForFirstConsumer claim once a pod using it is scheduled
+	annSelectedNode = "volume.kubernetes.io/selected-node"
+)
 
 // TriagePVC gets a coreclient and checks if there are any pvcs that are in lost state
 func TriagePVC(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	pvcs, err := coreClient.PersistentVolumeClaims("").List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
@@ -19,8 +31,175 @@ func TriagePVC(coreClient coreclient.CoreV1Interface) (*Triage, error) {
 
 	for _, i := range pvcs.Items {
 		if i.Status.Phase == pvcLostPhase {
-			listOfTriages = append(listOfTriages, i.GetName())
+			anomaly := i.GetNamespace() + "/" + i.GetName()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"PersistentVolumeClaim", i.GetNamespace(), i.GetName()}
+		}
+	}
+	return NewTriage("PVC", "Found PVC in Lost State!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriagePendingPVC gets a coreclient and checks if there are any pvcs that have been Pending for longer
+// than the given duration, reporting the latest ProvisioningFailed event explaining why.
+// Claims of a WaitForFirstConsumer StorageClass stay Pending until a pod using them is scheduled,
+// they are only reported once the scheduler selected a node for them
+func TriagePendingPVC(kubeCli *kubernetes.Clientset, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	coreClient := kubeCli.CoreV1()
+	pvcs, err := coreClient.PersistentVolumeClaims("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	storageClasses, err := kubeCli.StorageV1().StorageClasses().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	waitForConsumer := make(map[string]bool)
+	for _, i := range storageClasses.Items {
+		if mode := i.VolumeBindingMode; mode != nil && *mode == storagev1.VolumeBindingWaitForFirstConsumer {
+			waitForConsumer[i.GetName()] = true
+		}
+	}
+
+	currentTime := time.Now()
+	for _, i := range pvcs.Items {
+		if i.Status.Phase != corev1.ClaimPending || currentTime.Sub(i.GetCreationTimestamp().Time) <= threshold {
+			continue
+		}
+		if name := i.Spec.StorageClassName; name != nil && waitForConsumer[*name] && i.GetAnnotations()[annSelectedNode] == "" {
+			continue
+		}
+		anomaly := i.GetNamespace() + "/" + i.GetName()
+		events, err := coreClient.Events(i.GetNamespace()).List(v1.ListOptions{
+			FieldSelector: "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=" + i.GetName() +
+				",reason=" + pvcProvisioningFailed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var latest *corev1.Event
+		for idx, e := range events.Items {
+			if latest == nil || e.LastTimestamp.After(latest.LastTimestamp.Time) {
+				latest = &events.Items[idx]
+			}
+		}
+		if latest != nil {
+			anomaly += ": " + latest.Message
+		}
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = ObjectRef{"PersistentVolumeClaim", i.GetNamespace(), i.GetName()}
+	}
+	return NewTriage("PVC", "Found PVC stuck in Pending State for longer than "+threshold.String()+"!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriagePVCStorageClass gets a kubernetes.Clientset and checks if there are any pvcs not yet bound
+// referencing a StorageClass that does not exist. Bound claims are left out: statically provisioned
+// volumes commonly use a class name only to match claims to volumes, without a StorageClass object
+func TriagePVCStorageClass(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	pvcs, err := kubeCli.CoreV1().PersistentVolumeClaims("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	storageClasses, err := kubeCli.StorageV1().StorageClasses().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	existing := make(map[string]bool)
+	for _, i := range storageClasses.Items {
+		existing[i.GetName()] = true
+	}
+
+	for _, i := range pvcs.Items {
+		if i.Status.Phase == corev1.ClaimBound {
+			continue
+		}
+		// an empty class name explicitly asks for no dynamic provisioning
+		if name := i.Spec.StorageClassName; name != nil && *name != "" && !existing[*name] {
+			anomaly := i.GetNamespace() + "/" + i.GetName() + ": storage class " + *name
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"PersistentVolumeClaim", i.GetNamespace(), i.GetName()}
+		}
+	}
+	return NewTriage("PVC", "Found PVC referencing non-existent StorageClasses!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageUnusedPVC gets a coreclient and checks if there are any Bound pvcs created more than the given duration ago
+// that no running pod mounts. Deleted pods leave no trace, so the last consumer can only be told from finished pods
+// still referencing the claim: a claim whose last consumer finished within the duration is not reported
+func TriageUnusedPVC(coreClient coreclient.CoreV1Interface, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	pvcs, err := coreClient.PersistentVolumeClaims("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	pods, err := coreClient.Pods("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	mounted := make(map[string]bool)
+	// namespace/claim -> when the last finished pod mounting it stopped, and its name
+	lastUsed := make(map[string]time.Time)
+	lastConsumer := make(map[string]string)
+	for idx, p := range pods.Items {
+		finished := p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed
+		for _, v := range p.Spec.Volumes {
+			if v.PersistentVolumeClaim == nil {
+				continue
+			}
+			key := p.GetNamespace() + "/" + v.PersistentVolumeClaim.ClaimName
+			if !finished {
+				mounted[key] = true
+				continue
+			}
+			if at := podFinishedAt(&pods.Items[idx]); at.After(lastUsed[key]) {
+				lastUsed[key] = at
+				lastConsumer[key] = p.GetName()
+			}
+		}
+	}
+
+	currentTime := time.Now()
+	for _, i := range pvcs.Items {
+		key := i.GetNamespace() + "/" + i.GetName()
+		if i.Status.Phase != corev1.ClaimBound || mounted[key] || currentTime.Sub(i.GetCreationTimestamp().Time) <= threshold {
+			continue
+		}
+		anomaly := key + ": created " + currentTime.Sub(i.GetCreationTimestamp().Time).Round(time.Hour).String() + " ago"
+		if at, ok := lastUsed[key]; ok {
+			if currentTime.Sub(at) <= threshold {
+				continue
+			}
+			anomaly += ", last mounted by pod " + lastConsumer[key] + " finished " + currentTime.Sub(at).Round(time.Minute).String() + " ago"
+		}
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = ObjectRef{"PersistentVolumeClaim", i.GetNamespace(), i.GetName()}
+	}
+	return NewTriage("PVC", "Found Bound PVC not mounted by any running pod and created more than "+threshold.String()+" ago!", listOfTriages).WithObjects(objects), nil
+}
+
+// podFinishedAt returns when the last container of a finished pod terminated
+func podFinishedAt(pod *corev1.Pod) time.Time {
+	var finishedAt time.Time
+	for _, c := range pod.Status.ContainerStatuses {
+		if t := c.State.Terminated; t != nil && t.FinishedAt.After(finishedAt) {
+			finishedAt = t.FinishedAt.Time
 		}
 	}
-	return NewTriage("PVC", "Found PVC in Lost State!", listOfTriages), nil
+	return finishedAt
 }
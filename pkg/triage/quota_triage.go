@@ -0,0 +1,166 @@
+package triage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	eventReasonFailedCreate = "FailedCreate"
+	// the admission plugin's message when a pod would exceed a quota
+	quotaExceededMessage = "exceeded quota"
+)
+
+// TriageResourceQuotas gets a kubernetes.Clientset, a specific namespace string and a percentage
+// then proceeds to search ResourceQuotas whose usage of any resource is above that percentage of the hard limit,
+// and ReplicaSets failing to create pods because a quota is already exhausted
+func TriageResourceQuotas(kubeCli *kubernetes.Clientset, namespace string, thresholdPercent int64) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	quotas, err := kubeCli.CoreV1().ResourceQuotas(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	anomalyType := fmt.Sprintf("Found resource quotas above %d%% usage in namespace: %s", thresholdPercent, namespace)
+	if len(quotas.Items) == 0 {
+		return NewTriage("ResourceQuotas", anomalyType, listOfTriages), nil
+	}
+
+	for _, i := range quotas.Items {
+		names := make([]string, 0, len(i.Status.Hard))
+		for name := range i.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			hard := i.Status.Hard[corev1.ResourceName(name)]
+			used := i.Status.Used[corev1.ResourceName(name)]
+			if hard.IsZero() {
+				continue
+			}
+			percent := quantityPercent(used, hard)
+			if percent > thresholdPercent {
+				anomaly := fmt.Sprintf("%s: %s used %s of %s (%d%%)", i.GetName(), name, used.String(), hard.String(), percent)
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = ObjectRef{"ResourceQuota", namespace, i.GetName()}
+			}
+		}
+	}
+
+	events, err := kubeCli.CoreV1().Events(namespace).List(v1.ListOptions{
+		FieldSelector: "involvedObject.kind=ReplicaSet,reason=" + eventReasonFailedCreate,
+	})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	seen := make(map[string]bool)
+	for _, e := range events.Items {
+		if !strings.Contains(e.Message, quotaExceededMessage) || seen[e.InvolvedObject.Name] {
+			continue
+		}
+		seen[e.InvolvedObject.Name] = true
+		anomaly := "ReplicaSet/" + e.InvolvedObject.Name + ": " + e.Message
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = ObjectRef{"ReplicaSet", namespace, e.InvolvedObject.Name}
+	}
+	return NewTriage("ResourceQuotas", anomalyType, listOfTriages).WithObjects(objects), nil
+}
+
+// TriageLimitRanges gets a kubernetes.Clientset and a specific namespace string
+// then proceeds to search for LimitRange defaults that conflict with the ResourceQuotas of the namespace:
+// quotas on requests/limits that no LimitRange provides a default for, which makes pods without explicit
+// values get rejected, and LimitRange defaults that are bigger than the quota itself
+func TriageLimitRanges(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	quotas, err := kubeCli.CoreV1().ResourceQuotas(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	anomalyType := "Found limit range defaults conflicting with resource quotas in namespace: " + namespace
+	if len(quotas.Items) == 0 {
+		return NewTriage("LimitRanges", anomalyType, listOfTriages), nil
+	}
+	limitRanges, err := kubeCli.CoreV1().LimitRanges(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	defaultRequests, defaultLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, l := range limitRanges.Items {
+		for _, item := range l.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, quantity := range item.DefaultRequest {
+				defaultRequests[name] = quantity
+			}
+			for name, quantity := range item.Default {
+				defaultLimits[name] = quantity
+			}
+		}
+	}
+
+	for _, i := range quotas.Items {
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			checks := []struct {
+				kind      string
+				quotaKeys []corev1.ResourceName
+				defaults  corev1.ResourceList
+			}{
+				{"request", []corev1.ResourceName{r, corev1.ResourceName("requests." + string(r))}, defaultRequests},
+				{"limit", []corev1.ResourceName{corev1.ResourceName("limits." + string(r))}, defaultLimits},
+			}
+			for _, check := range checks {
+				for _, key := range check.quotaKeys {
+					hard, ok := i.Spec.Hard[key]
+					if !ok {
+						continue
+					}
+					def, hasDefault := check.defaults[r]
+					// a default limit also serves as default request
+					if !hasDefault && check.kind == "request" {
+						def, hasDefault = defaultLimits[r]
+					}
+					var anomaly string
+					if !hasDefault {
+						anomaly = fmt.Sprintf("%s: quota on %s but no limit range default %s, pods without one are rejected",
+							i.GetName(), key, check.kind)
+					} else if def.Cmp(hard) > 0 {
+						anomaly = fmt.Sprintf("%s: limit range default %s %s %s is bigger than quota %s %s",
+							i.GetName(), r, check.kind, def.String(), key, hard.String())
+					} else {
+						continue
+					}
+					listOfTriages = append(listOfTriages, anomaly)
+					objects[anomaly] = ObjectRef{"ResourceQuota", namespace, i.GetName()}
+				}
+			}
+		}
+	}
+	return NewTriage("LimitRanges", anomalyType, listOfTriages).WithObjects(objects), nil
+}
+
+// quantityPercent returns used as a percentage of total, computed on the decimal values
+// since milli values of storage quotas in the Ti range overflow int64 once multiplied
+func quantityPercent(used, total resource.Quantity) int64 {
+	u, _ := strconv.ParseFloat(used.AsDec().String(), 64)
+	t, _ := strconv.ParseFloat(total.AsDec().String(), 64)
+	return int64(u * 100 / t)
+}
@@ -0,0 +1,224 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	clusterAdminRole = "cluster-admin"
+	systemPrefix     = "system:"
+)
+
+// isSystemSubject reports whether a binding subject belongs to kubernetes itself:
+// system: users and groups, and service accounts of kube-system
+func isSystemSubject(subject rbacv1.Subject) bool {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		return subject.Namespace == kubeSystemNamespace
+	}
+	return strings.HasPrefix(subject.Name, systemPrefix)
+}
+
+// subjectString formats a subject as Kind/name, with the namespace for service accounts
+func subjectString(subject rbacv1.Subject) string {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		return subject.Kind + "/" + subject.Namespace + "/" + subject.Name
+	}
+	return subject.Kind + "/" + subject.Name
+}
+
+// bindingString formats a binding as Kind/name, with the namespace for role bindings
+func bindingString(binding ObjectRef) string {
+	if binding.Namespace != "" {
+		return binding.Kind + "/" + binding.Namespace + "/" + binding.Name
+	}
+	return binding.Kind + "/" + binding.Name
+}
+
+// hasWildcardRule reports whether any rule of a role grants every verb or every resource
+func hasWildcardRule(rules []rbacv1.PolicyRule) bool {
+	for _, r := range rules {
+		for _, verb := range r.Verbs {
+			if verb == rbacv1.VerbAll {
+				return true
+			}
+		}
+		for _, resource := range r.Resources {
+			if resource == rbacv1.ResourceAll {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TriageRBACPrivileges gets a kubernetes.Clientset and checks if there are ClusterRoleBindings
+// granting cluster-admin, or a ClusterRole with wildcard verbs/resources, to subjects that are not part of kubernetes
+func TriageRBACPrivileges(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	clusterRoles, err := kubeCli.RbacV1().ClusterRoles().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	clusterRoleBindings, err := kubeCli.RbacV1().ClusterRoleBindings().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	wildcardRoles := make(map[string]bool)
+	for _, i := range clusterRoles.Items {
+		if hasWildcardRule(i.Rules) {
+			wildcardRoles[i.GetName()] = true
+		}
+	}
+
+	for _, i := range clusterRoleBindings.Items {
+		role := i.RoleRef.Name
+		if role != clusterAdminRole && !wildcardRoles[role] {
+			continue
+		}
+		subjects := make([]string, 0)
+		for _, s := range i.Subjects {
+			if !isSystemSubject(s) {
+				subjects = append(subjects, subjectString(s))
+			}
+		}
+		if len(subjects) > 0 {
+			anomaly := i.GetName() + ": ClusterRole/" + role + " granted to " + strings.Join(subjects, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"ClusterRoleBinding", "", i.GetName()}
+		}
+	}
+	return NewTriage("ClusterRoleBindings", "Found cluster-admin or wildcard permissions granted to non-system subjects!", listOfTriages).WithObjects(objects), nil
+}
+
+// TriageRBACDanglingBindings gets a kubernetes.Clientset and checks if there are RoleBindings or ClusterRoleBindings
+// referencing Roles/ClusterRoles or ServiceAccounts that do not exist
+func TriageRBACDanglingBindings(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	clusterRoles, err := kubeCli.RbacV1().ClusterRoles().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	roles, err := kubeCli.RbacV1().Roles("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	serviceAccounts, err := kubeCli.CoreV1().ServiceAccounts("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	clusterRoleBindings, err := kubeCli.RbacV1().ClusterRoleBindings().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	roleBindings, err := kubeCli.RbacV1().RoleBindings("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, i := range clusterRoles.Items {
+		existing["ClusterRole/"+i.GetName()] = true
+	}
+	for _, i := range roles.Items {
+		existing["Role/"+i.GetNamespace()+"/"+i.GetName()] = true
+	}
+	for _, i := range serviceAccounts.Items {
+		existing["ServiceAccount/"+i.GetNamespace()+"/"+i.GetName()] = true
+	}
+
+	checkBinding := func(binding ObjectRef, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) {
+		namespace := binding.Namespace
+		issues := make([]string, 0)
+		role := roleRef.Kind + "/" + roleRef.Name
+		if roleRef.Kind == "Role" {
+			role = roleRef.Kind + "/" + namespace + "/" + roleRef.Name
+		}
+		if !existing[role] {
+			issues = append(issues, "missing "+role)
+		}
+		for _, s := range subjects {
+			// a service account subject of a RoleBinding defaults to the binding's namespace
+			if s.Kind == rbacv1.ServiceAccountKind && s.Namespace == "" {
+				s.Namespace = namespace
+			}
+			if s.Kind == rbacv1.ServiceAccountKind && !existing[subjectString(s)] {
+				issues = append(issues, "missing "+subjectString(s))
+			}
+		}
+		if len(issues) > 0 {
+			anomaly := bindingString(binding) + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = binding
+		}
+	}
+	for _, i := range clusterRoleBindings.Items {
+		checkBinding(ObjectRef{"ClusterRoleBinding", "", i.GetName()}, i.RoleRef, i.Subjects)
+	}
+	for _, i := range roleBindings.Items {
+		checkBinding(ObjectRef{"RoleBinding", i.GetNamespace(), i.GetName()}, i.RoleRef, i.Subjects)
+	}
+	sort.Strings(listOfTriages)
+	return NewTriage("RoleBindings", "Found bindings referencing roles or service accounts that do not exist!", listOfTriages).WithObjects(objects), nil
+}
+
+// TriageDefaultServiceAccountBindings gets a kubernetes.Clientset and checks if there are bindings granting
+// permissions to the default ServiceAccount of a namespace, which every pod not asking for a specific
+// service account runs with
+func TriageDefaultServiceAccountBindings(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	clusterRoleBindings, err := kubeCli.RbacV1().ClusterRoleBindings().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	roleBindings, err := kubeCli.RbacV1().RoleBindings("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	checkBinding := func(binding ObjectRef, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) {
+		for _, s := range subjects {
+			if s.Kind == rbacv1.ServiceAccountKind && s.Namespace == "" {
+				s.Namespace = binding.Namespace
+			}
+			if s.Kind == rbacv1.ServiceAccountKind && s.Name == defaultServiceAccount && s.Namespace != kubeSystemNamespace {
+				anomaly := bindingString(binding) + ": " + roleRef.Kind + "/" + roleRef.Name + " granted to " + subjectString(s)
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = binding
+			}
+		}
+	}
+	for _, i := range clusterRoleBindings.Items {
+		checkBinding(ObjectRef{"ClusterRoleBinding", "", i.GetName()}, i.RoleRef, i.Subjects)
+	}
+	for _, i := range roleBindings.Items {
+		checkBinding(ObjectRef{"RoleBinding", i.GetNamespace(), i.GetName()}, i.RoleRef, i.Subjects)
+	}
+	return NewTriage("RoleBindings", "Found permissions granted to default service accounts!", listOfTriages).WithObjects(objects), nil
+}
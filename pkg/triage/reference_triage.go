@@ -0,0 +1,154 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultServiceAccount = "default"
+
+// TriageBrokenReferences gets a kubernetes.Clientset, a dynamic client and a specific namespace string
+// then proceeds to search pods, workloads, jobs and cronjobs referencing ConfigMaps, Secrets, keys within them,
+// PVCs, ServiceAccounts or PriorityClasses that do not exist. Those only surface as CreateContainerConfigError
+// or FailedCreate events otherwise
+func TriageBrokenReferences(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	specs, err := listPodSpecs(kubeCli, dynamicCli, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return NewTriage("References", "Found broken references in namespace: "+namespace, listOfTriages), nil
+	}
+
+	configMaps, err := kubeCli.CoreV1().ConfigMaps(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	configMapKeys := make(map[string]map[string]bool)
+	for _, i := range configMaps.Items {
+		keys := make(map[string]bool)
+		for k := range i.Data {
+			keys[k] = true
+		}
+		for k := range i.BinaryData {
+			keys[k] = true
+		}
+		configMapKeys[i.GetName()] = keys
+	}
+
+	secrets, err := kubeCli.CoreV1().Secrets(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	secretKeys := make(map[string]map[string]bool)
+	for _, i := range secrets.Items {
+		keys := make(map[string]bool)
+		for k := range i.Data {
+			keys[k] = true
+		}
+		secretKeys[i.GetName()] = keys
+	}
+
+	pvcs, err := kubeCli.CoreV1().PersistentVolumeClaims(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	existingPVCs := make(map[string]bool)
+	for _, i := range pvcs.Items {
+		existingPVCs[i.GetName()] = true
+	}
+
+	serviceAccounts, err := kubeCli.CoreV1().ServiceAccounts(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	existingServiceAccounts := make(map[string]bool)
+	for _, i := range serviceAccounts.Items {
+		existingServiceAccounts[i.GetName()] = true
+	}
+
+	priorityClasses := make(map[string]bool)
+	for key, spec := range specs {
+		refs := collectPodReferences(spec)
+		issues := make([]string, 0)
+
+		for name := range refs.ConfigMaps {
+			if _, ok := configMapKeys[name]; !ok && !refs.Optional["ConfigMap/"+name] {
+				issues = append(issues, "missing ConfigMap "+name)
+			}
+		}
+		for name, keys := range refs.ConfigMapKeys {
+			existing, ok := configMapKeys[name]
+			for _, k := range keys {
+				if ok && !existing[k] {
+					issues = append(issues, "missing key "+k+" in ConfigMap "+name)
+				}
+			}
+		}
+		for name := range refs.Secrets {
+			if _, ok := secretKeys[name]; !ok && !refs.Optional["Secret/"+name] {
+				issues = append(issues, "missing Secret "+name)
+			}
+		}
+		for name, keys := range refs.SecretKeys {
+			existing, ok := secretKeys[name]
+			for _, k := range keys {
+				if ok && !existing[k] {
+					issues = append(issues, "missing key "+k+" in Secret "+name)
+				}
+			}
+		}
+		for name := range refs.PVCs {
+			if !existingPVCs[name] {
+				issues = append(issues, "missing PersistentVolumeClaim "+name)
+			}
+		}
+
+		serviceAccount := refs.ServiceAccount
+		if serviceAccount == "" {
+			serviceAccount = defaultServiceAccount
+		}
+		if !existingServiceAccounts[serviceAccount] {
+			issues = append(issues, "missing ServiceAccount "+serviceAccount)
+		}
+
+		if name := refs.PriorityClass; name != "" {
+			exists, checked := priorityClasses[name]
+			if !checked {
+				_, err := kubeCli.SchedulingV1().PriorityClasses().Get(name, v1.GetOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				exists = err == nil
+				priorityClasses[name] = exists
+			}
+			if !exists {
+				issues = append(issues, "missing PriorityClass "+name)
+			}
+		}
+
+		if len(issues) > 0 {
+			sort.Strings(issues)
+			anomaly := key + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = specObject(key, namespace)
+		}
+	}
+	sort.Strings(listOfTriages)
+	return NewTriage("References", "Found broken references in namespace: "+namespace, listOfTriages).WithObjects(objects).AsCausal(), nil
+}
@@ -0,0 +1,207 @@
+package triage
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	ingressesV1      = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	ingressesV1beta1 = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}
+)
+
+// podReferences holds the names of the objects a pod spec depends on
+type podReferences struct {
+	ConfigMaps     map[string]bool
+	Secrets        map[string]bool
+	PVCs           map[string]bool
+	ServiceAccount string
+	PriorityClass  string
+	// keys of ConfigMaps/Secrets referenced one by one by env valueFrom, by object name
+	ConfigMapKeys map[string][]string
+	SecretKeys    map[string][]string
+	// references marked optional do not break the pod when missing
+	Optional map[string]bool
+}
+
+// collectPodReferences walks volumes, projected volumes, envFrom, env valueFrom and imagePullSecrets of a pod spec
+func collectPodReferences(spec *corev1.PodSpec) podReferences {
+	refs := podReferences{
+		ConfigMaps:     make(map[string]bool),
+		Secrets:        make(map[string]bool),
+		PVCs:           make(map[string]bool),
+		ServiceAccount: spec.ServiceAccountName,
+		PriorityClass:  spec.PriorityClassName,
+		ConfigMapKeys:  make(map[string][]string),
+		SecretKeys:     make(map[string][]string),
+		Optional:       make(map[string]bool),
+	}
+	isOptional := func(optional *bool) bool { return optional != nil && *optional }
+	addConfigMap := func(name string, optional *bool) {
+		refs.ConfigMaps[name] = true
+		if isOptional(optional) {
+			refs.Optional["ConfigMap/"+name] = true
+		}
+	}
+	addSecret := func(name string, optional *bool) {
+		refs.Secrets[name] = true
+		if isOptional(optional) {
+			refs.Optional["Secret/"+name] = true
+		}
+	}
+
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			addConfigMap(v.ConfigMap.Name, v.ConfigMap.Optional)
+		}
+		if v.Secret != nil {
+			addSecret(v.Secret.SecretName, v.Secret.Optional)
+		}
+		if v.PersistentVolumeClaim != nil {
+			refs.PVCs[v.PersistentVolumeClaim.ClaimName] = true
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil {
+					addConfigMap(s.ConfigMap.Name, s.ConfigMap.Optional)
+				}
+				if s.Secret != nil {
+					addSecret(s.Secret.Name, s.Secret.Optional)
+				}
+			}
+		}
+	}
+	for _, c := range podContainers(spec) {
+		for _, e := range c.EnvFrom {
+			if e.ConfigMapRef != nil {
+				addConfigMap(e.ConfigMapRef.Name, e.ConfigMapRef.Optional)
+			}
+			if e.SecretRef != nil {
+				addSecret(e.SecretRef.Name, e.SecretRef.Optional)
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if ref := e.ValueFrom.ConfigMapKeyRef; ref != nil {
+				addConfigMap(ref.Name, ref.Optional)
+				if !isOptional(ref.Optional) {
+					refs.ConfigMapKeys[ref.Name] = append(refs.ConfigMapKeys[ref.Name], ref.Key)
+				}
+			}
+			if ref := e.ValueFrom.SecretKeyRef; ref != nil {
+				addSecret(ref.Name, ref.Optional)
+				if !isOptional(ref.Optional) {
+					refs.SecretKeys[ref.Name] = append(refs.SecretKeys[ref.Name], ref.Key)
+				}
+			}
+		}
+	}
+	for _, s := range spec.ImagePullSecrets {
+		// pods still start when an image pull secret is missing
+		addSecret(s.Name, nil)
+		refs.Optional["Secret/"+s.Name] = true
+	}
+	return refs
+}
+
+// listPodSpecs gets a kubernetes.Clientset, a dynamic client and a specific namespace string
+// then returns the specs of the pods and of the pod templates of workloads, Jobs and CronJobs in it,
+// keyed by the object owning them, so scaled down or suspended workloads are taken into account too.
+// When includeControlled is false, pods and Jobs created by a controller are left out as their template is already listed
+func listPodSpecs(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface, namespace string, includeControlled bool) (map[string]*corev1.PodSpec, error) {
+	specs := make(map[string]*corev1.PodSpec)
+
+	pods, err := kubeCli.CoreV1().Pods(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for idx, i := range pods.Items {
+		if !includeControlled && v1.GetControllerOf(&pods.Items[idx]) != nil {
+			continue
+		}
+		specs["Pod/"+i.GetName()] = &pods.Items[idx].Spec
+	}
+
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for idx, w := range workloads {
+		specs[w.String()] = &workloads[idx].Template.Spec
+	}
+
+	jobs, err := kubeCli.BatchV1().Jobs(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for idx, i := range jobs.Items {
+		if !includeControlled && v1.GetControllerOf(&jobs.Items[idx]) != nil {
+			continue
+		}
+		specs["Job/"+i.GetName()] = &jobs.Items[idx].Spec.Template.Spec
+	}
+
+	cronJobs, err := listCronJobs(dynamicCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range cronJobs.Items {
+		template, ok, _ := unstructured.NestedMap(i.Object, "spec", "jobTemplate", "spec", "template", "spec")
+		if !ok {
+			continue
+		}
+		spec := &corev1.PodSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, spec); err != nil {
+			return nil, err
+		}
+		specs["CronJob/"+i.GetName()] = spec
+	}
+	return specs, nil
+}
+
+// ingressTLSSecrets gets a dynamic client and a specific namespace string then returns the names of the secrets
+// the ingresses in it terminate TLS with, read from networking.k8s.io/v1 or v1beta1 on older servers
+func ingressTLSSecrets(dynamicCli dynamic.Interface, namespace string) ([]string, error) {
+	secrets := make([]string, 0)
+	ingresses, err := dynamicCli.Resource(ingressesV1).Namespace(namespace).List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		ingresses, err = dynamicCli.Resource(ingressesV1beta1).Namespace(namespace).List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return secrets, nil
+	}
+	for _, i := range ingresses.Items {
+		tls, _, _ := unstructured.NestedSlice(i.Object, "spec", "tls")
+		for _, t := range tls {
+			if entry, ok := t.(map[string]interface{}); ok {
+				if name, _ := entry["secretName"].(string); name != "" {
+					secrets = append(secrets, name)
+				}
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// specObject turns a key of listPodSpecs back into the object owning the spec
+func specObject(key string, namespace string) ObjectRef {
+	parts := strings.SplitN(key, "/", 2)
+	return ObjectRef{parts[0], namespace, parts[1]}
+}
@@ -10,6 +10,7 @@ import (
 // the criteria is that the desired number of replicas are bigger than 0 but the available replicas are 0
 func OrphanedReplicaSet(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	rs, err := kubeCli.AppsV1beta2().ReplicaSets(namespace).List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
@@ -20,9 +21,10 @@ func OrphanedReplicaSet(kubeCli *kubernetes.Clientset, namespace string) (*Triag
 	for _, i := range rs.Items {
 		if i.Status.Replicas > 0 && i.Status.AvailableReplicas == 0 {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"ReplicaSet", namespace, i.GetName()}
 		}
 	}
-	return NewTriage("ReplicaSets", "Found orphan replicasets in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("ReplicaSets", "Found orphan replicasets in namespace: "+namespace, listOfTriages).WithObjects(objects).AsCausal(), nil
 }
 
 // LeftOverReplicaSet gets a kubernetes.Clientset and a specific namespace string
@@ -30,6 +32,7 @@ func OrphanedReplicaSet(kubeCli *kubernetes.Clientset, namespace string) (*Triag
 // the criteria is that both the desired number of replicas and the available # of replicas are 0
 func LeftOverReplicaSet(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
 	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
 	rs, err := kubeCli.AppsV1beta2().ReplicaSets(namespace).List(v1.ListOptions{})
 	if err != nil {
 		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
@@ -40,7 +43,8 @@ func LeftOverReplicaSet(kubeCli *kubernetes.Clientset, namespace string) (*Triag
 	for _, i := range rs.Items {
 		if i.Status.Replicas == 0 && i.Status.AvailableReplicas == 0 {
 			listOfTriages = append(listOfTriages, i.GetName())
+			objects[i.GetName()] = ObjectRef{"ReplicaSet", namespace, i.GetName()}
 		}
 	}
-	return NewTriage("ReplicaSets", "Found leftover replicasets in namespace: "+namespace, listOfTriages), nil
+	return NewTriage("ReplicaSets", "Found leftover replicasets in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
 }
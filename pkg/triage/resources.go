@@ -0,0 +1,34 @@
+package triage
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podRequests computes the effective resource requests of a pod the same way the scheduler does:
+// the sum of all app containers, or the largest init container if that is bigger
+func podRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	reqs := corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		addResourceList(reqs, c.Resources.Requests)
+	}
+	for _, c := range spec.InitContainers {
+		for name, quantity := range c.Resources.Requests {
+			if value, ok := reqs[name]; !ok || quantity.Cmp(value) > 0 {
+				reqs[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return reqs
+}
+
+// addResourceList adds the resources in newList to list
+func addResourceList(list, newList corev1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok {
+			list[name] = quantity.DeepCopy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
+}
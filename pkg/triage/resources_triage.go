@@ -0,0 +1,92 @@
+package triage
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TriageResourceHygiene gets a kubernetes.Clientset and a specific namespace string
+// then proceeds to search workloads whose containers have no cpu/memory requests nor limits to default them from, no memory limit
+// or limits that are more than limitRatio times their requests, one entry per workload
+func TriageResourceHygiene(kubeCli *kubernetes.Clientset, namespace string, limitRatio float64) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range workloads {
+		issues := make([]string, 0)
+		for _, c := range w.Template.Spec.Containers {
+			// a request left out defaults to the limit when the pod is created
+			for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				_, hasRequest := c.Resources.Requests[r]
+				_, hasLimit := c.Resources.Limits[r]
+				if !hasRequest && !hasLimit {
+					issues = append(issues, c.Name+" has no "+string(r)+" request")
+				}
+			}
+			if _, ok := c.Resources.Limits[corev1.ResourceMemory]; !ok {
+				issues = append(issues, c.Name+" has no memory limit")
+			}
+			for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				request, hasRequest := c.Resources.Requests[r]
+				limit, hasLimit := c.Resources.Limits[r]
+				if !hasRequest || !hasLimit || request.IsZero() {
+					continue
+				}
+				ratio := float64(limit.MilliValue()) / float64(request.MilliValue())
+				if ratio > limitRatio {
+					issues = append(issues, fmt.Sprintf("%s %s limit %s is %.1fx its request %s",
+						c.Name, r, limit.String(), ratio, request.String()))
+				}
+			}
+		}
+		if len(issues) > 0 {
+			anomaly := w.String() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = w.ref()
+		}
+	}
+	return NewTriage("Workloads", "Found workloads with missing or unbalanced resource requests/limits in namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// TriageBestEffortWorkloads gets a kubernetes.Clientset and a specific namespace string
+// then proceeds to search workloads whose pods would get the BestEffort QoS class,
+// which are the first ones to be evicted under node pressure
+func TriageBestEffortWorkloads(kubeCli *kubernetes.Clientset, namespace string) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	workloads, err := listWorkloads(kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range workloads {
+		if isBestEffort(&w.Template.Spec) {
+			anomaly := w.String()
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = w.ref()
+		}
+	}
+	return NewTriage("Workloads", "Found BestEffort QoS workloads in production namespace: "+namespace, listOfTriages).WithObjects(objects), nil
+}
+
+// isBestEffort reports whether no container of the pod sets any cpu/memory request or limit
+func isBestEffort(spec *corev1.PodSpec) bool {
+	for _, c := range podContainers(spec) {
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := c.Resources.Requests[r]; ok {
+				return false
+			}
+			if _, ok := c.Resources.Limits[r]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// listStorageObjects gets a dynamic client and a cluster scoped storage.k8s.io resource then lists it from
+// storage.k8s.io/v1, falling back to v1beta1 on servers that predate it. CSIDrivers and CSINodes are read
+// this way as the typed client doctor is built with only knows their v1beta1 version
+func listStorageObjects(dynamicCli dynamic.Interface, resource string) (*unstructured.UnstructuredList, error) {
+	list, err := dynamicCli.Resource(schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: resource}).
+		List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		list, err = dynamicCli.Resource(schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1beta1", Resource: resource}).
+			List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return &unstructured.UnstructuredList{}, nil
+	}
+	return list, nil
+}
+
+// csiNodeDrivers returns the names of the drivers registered in a CSINode object
+func csiNodeDrivers(csiNode *unstructured.Unstructured) []string {
+	names := make([]string, 0)
+	drivers, _, _ := unstructured.NestedSlice(csiNode.Object, "spec", "drivers")
+	for _, d := range drivers {
+		if driver, ok := d.(map[string]interface{}); ok {
+			if name, _ := driver["name"].(string); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// isDefaultStorageClass checks the GA and beta default class annotations
+func isDefaultStorageClass(sc *storagev1.StorageClass) bool {
+	return sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true"
+}
+
+// TriageDefaultStorageClass gets a kubernetes.Clientset and checks if the cluster has no default StorageClass,
+// which leaves claims without a class Pending, or more than one, which makes claims without a class get rejected
+func TriageDefaultStorageClass(kubeCli *kubernetes.Clientset) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	storageClasses, err := kubeCli.StorageV1().StorageClasses().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	defaults := make([]string, 0)
+	for idx, i := range storageClasses.Items {
+		if isDefaultStorageClass(&storageClasses.Items[idx]) {
+			defaults = append(defaults, i.GetName())
+		}
+	}
+	if len(storageClasses.Items) > 0 && len(defaults) == 0 {
+		listOfTriages = append(listOfTriages, "no default storage class")
+	}
+	if len(defaults) > 1 {
+		listOfTriages = append(listOfTriages, "multiple default storage classes: "+strings.Join(defaults, ", "))
+	}
+	return NewTriage("StorageClasses", "Found default StorageClass misconfiguration!", listOfTriages), nil
+}
+
+// TriageCSIDrivers gets a kubernetes.Clientset and a dynamic client and checks if there are StorageClasses
+// whose CSI provisioner has no CSIDriver object, or whose driver is not registered on any node (no node plugin
+// pod running). A provisioner is only known to be a CSI driver when a CSIDriver object, a CSINode registration
+// or a CSI PersistentVolume names it, other external provisioners such as local-path or nfs are left alone
+func TriageCSIDrivers(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	storageClasses, err := kubeCli.StorageV1().StorageClasses().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	csiDrivers, err := listStorageObjects(dynamicCli, "csidrivers")
+	if err != nil {
+		return nil, err
+	}
+	csiNodes, err := listStorageObjects(dynamicCli, "csinodes")
+	if err != nil {
+		return nil, err
+	}
+	pvs, err := kubeCli.CoreV1().PersistentVolumes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	drivers := make(map[string]bool)
+	for _, i := range csiDrivers.Items {
+		drivers[i.GetName()] = true
+	}
+	registrations := make(map[string]int)
+	for idx := range csiNodes.Items {
+		for _, name := range csiNodeDrivers(&csiNodes.Items[idx]) {
+			registrations[name]++
+		}
+	}
+	volumeDrivers := make(map[string]bool)
+	for _, i := range pvs.Items {
+		if i.Spec.CSI != nil {
+			volumeDrivers[i.Spec.CSI.Driver] = true
+		}
+	}
+
+	for _, i := range storageClasses.Items {
+		if !drivers[i.Provisioner] && registrations[i.Provisioner] == 0 && !volumeDrivers[i.Provisioner] {
+			continue
+		}
+		issues := make([]string, 0)
+		if !drivers[i.Provisioner] {
+			issues = append(issues, "no CSIDriver object for "+i.Provisioner)
+		}
+		if registrations[i.Provisioner] == 0 {
+			issues = append(issues, "no node plugin of "+i.Provisioner+" registered on any node")
+		}
+		if len(issues) > 0 {
+			anomaly := i.GetName() + ": " + strings.Join(issues, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"StorageClass", "", i.GetName()}
+		}
+	}
+	return NewTriage("StorageClasses", "Found StorageClasses whose CSI driver is missing!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageVolumeAttachments gets a kubernetes.Clientset and checks if there are VolumeAttachments
+// that are still not attached after the given duration, reporting their attach error
+func TriageVolumeAttachments(kubeCli *kubernetes.Clientset, threshold time.Duration) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	attachments, err := kubeCli.StorageV1().VolumeAttachments().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+
+	currentTime := time.Now()
+	for _, i := range attachments.Items {
+		if i.Status.Attached || i.GetDeletionTimestamp() != nil || currentTime.Sub(i.GetCreationTimestamp().Time) <= threshold {
+			continue
+		}
+		anomaly := i.GetName() + ": " + i.Spec.Attacher + " on node " + i.Spec.NodeName
+		if pv := i.Spec.Source.PersistentVolumeName; pv != nil {
+			anomaly += " for PV " + *pv
+		}
+		if attachError := i.Status.AttachError; attachError != nil {
+			anomaly += " (" + attachError.Message + ")"
+		}
+		listOfTriages = append(listOfTriages, anomaly)
+		objects[anomaly] = ObjectRef{"VolumeAttachment", "", i.GetName()}
+	}
+	return NewTriage("VolumeAttachments", "Found VolumeAttachments not attached after "+threshold.String()+"!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
+
+// TriageCSINodes gets a kubernetes.Clientset and a dynamic client and checks if there are nodes running pods that mount CSI volumes
+// whose driver is not registered in the node's CSINode object
+func TriageCSINodes(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	pvs, err := kubeCli.CoreV1().PersistentVolumes().List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	// namespace/claim -> csi driver
+	claimDrivers := make(map[string]string)
+	for _, i := range pvs.Items {
+		if i.Spec.CSI != nil && i.Spec.ClaimRef != nil {
+			claimDrivers[i.Spec.ClaimRef.Namespace+"/"+i.Spec.ClaimRef.Name] = i.Spec.CSI.Driver
+		}
+	}
+	if len(claimDrivers) == 0 {
+		return NewTriage("CSINodes", "Found nodes missing CSI drivers their pods need!", listOfTriages), nil
+	}
+
+	csiNodes, err := listStorageObjects(dynamicCli, "csinodes")
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]map[string]bool)
+	for idx, i := range csiNodes.Items {
+		registered[i.GetName()] = make(map[string]bool)
+		for _, name := range csiNodeDrivers(&csiNodes.Items[idx]) {
+			registered[i.GetName()][name] = true
+		}
+	}
+
+	pods, err := kubeCli.CoreV1().Pods("").List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	// node -> missing driver -> pods
+	missing := make(map[string]map[string][]string)
+	for _, p := range pods.Items {
+		node := p.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		for _, v := range p.Spec.Volumes {
+			if v.PersistentVolumeClaim == nil {
+				continue
+			}
+			driver, ok := claimDrivers[p.GetNamespace()+"/"+v.PersistentVolumeClaim.ClaimName]
+			if !ok || registered[node][driver] {
+				continue
+			}
+			if missing[node] == nil {
+				missing[node] = make(map[string][]string)
+			}
+			missing[node][driver] = append(missing[node][driver], p.GetNamespace()+"/"+p.GetName())
+		}
+	}
+
+	for node, drivers := range missing {
+		for driver, needingPods := range drivers {
+			anomaly := node + ": driver " + driver + " not registered, needed by " + strings.Join(needingPods, ", ")
+			listOfTriages = append(listOfTriages, anomaly)
+			objects[anomaly] = ObjectRef{"Node", "", node}
+		}
+	}
+	sort.Strings(listOfTriages)
+	return NewTriage("CSINodes", "Found nodes missing CSI drivers their pods need!", listOfTriages).WithObjects(objects).AsCausal(), nil
+}
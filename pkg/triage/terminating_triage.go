@@ -0,0 +1,71 @@
+package triage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// namespace conditions explaining what blocks the deletion of a Terminating namespace
+var namespaceDeletionConditions = map[string]bool{
+	"NamespaceDeletionDiscoveryFailure":           true,
+	"NamespaceDeletionGroupVersionParsingFailure": true,
+	"NamespaceDeletionContentFailure":             true,
+	"NamespaceContentRemaining":                   true,
+	"NamespaceFinalizersRemaining":                true,
+}
+
+// terminatingCheck searches any object, custom resources included, whose deletionTimestamp is older than the threshold,
+// reporting its remaining finalizers and, for namespaces, the conditions explaining what blocks the deletion
+type terminatingCheck struct {
+	threshold     time.Duration
+	currentTime   time.Time
+	listOfTriages []string
+	objects       map[string]ObjectRef
+}
+
+func newTerminatingCheck(threshold time.Duration) *terminatingCheck {
+	return &terminatingCheck{threshold, time.Now(), make([]string, 0), make(map[string]ObjectRef)}
+}
+
+func (c *terminatingCheck) visit(resource v1.APIResource, object *unstructured.Unstructured) {
+	deletionTimestamp := object.GetDeletionTimestamp()
+	if deletionTimestamp == nil {
+		return
+	}
+	terminatingFor := c.currentTime.Sub(deletionTimestamp.Time)
+	if terminatingFor <= c.threshold {
+		return
+	}
+
+	anomaly := objectKey(resource.Kind, object) + ": terminating for " + terminatingFor.Round(time.Minute).String()
+	if finalizers := object.GetFinalizers(); len(finalizers) > 0 {
+		anomaly += ", finalizers: " + strings.Join(finalizers, ", ")
+	}
+	if resource.Kind == "Namespace" {
+		if specFinalizers, _, _ := unstructured.NestedStringSlice(object.Object, "spec", "finalizers"); len(specFinalizers) > 0 {
+			anomaly += ", spec finalizers: " + strings.Join(specFinalizers, ", ")
+		}
+		conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+		for _, i := range conditions {
+			condition, ok := i.(map[string]interface{})
+			if !ok || condition["status"] != "True" {
+				continue
+			}
+			if conditionType, _ := condition["type"].(string); namespaceDeletionConditions[conditionType] {
+				message, _ := condition["message"].(string)
+				anomaly += ", " + conditionType + ": " + message
+			}
+		}
+	}
+	c.listOfTriages = append(c.listOfTriages, anomaly)
+	c.objects[anomaly] = ObjectRef{resource.Kind, object.GetNamespace(), object.GetName()}
+}
+
+func (c *terminatingCheck) triage() (*Triage, error) {
+	sort.Strings(c.listOfTriages)
+	return NewTriage("Objects", "Found objects stuck in Terminating for longer than "+c.threshold.String()+"!", c.listOfTriages).WithObjects(c.objects), nil
+}
@@ -7,6 +7,28 @@ type Triage struct {
 	ResourceType string   `yaml:"Resource"`
 	AnomalyType  string   `yaml:"AnomalyType"`
 	Anomalies    []string `yaml:"Anomalies"`
+	// Evidence holds recent warning events backing an anomaly, keyed by the anomaly
+	Evidence map[string][]string `yaml:"Evidence,omitempty"`
+	// Symptoms holds the findings caused by an anomaly, collapsed beneath it, keyed by the anomaly
+	Symptoms map[string][]string `yaml:"Symptoms,omitempty"`
+	// Objects maps an anomaly to the object it was found on, when it is about a single object
+	Objects map[string]ObjectRef `yaml:"-"`
+	// Causal is set on availability and health findings, only those are correlated into root causes and symptoms
+	Causal bool `yaml:"-"`
+}
+
+// ObjectRef identifies a kubernetes object, Namespace is empty for cluster scoped objects
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
 }
 
 func NewTriage(resourceType string, anomalyType string, anomalies []string) *Triage {
@@ -16,3 +38,15 @@ func NewTriage(resourceType string, anomalyType string, anomalies []string) *Tri
 		Anomalies:    anomalies,
 	}
 }
+
+// WithObjects records the object each anomaly was found on
+func (t *Triage) WithObjects(objects map[string]ObjectRef) *Triage {
+	t.Objects = objects
+	return t
+}
+
+// AsCausal marks the findings as availability or health problems that can cause others
+func (t *Triage) AsCausal() *Triage {
+	t.Causal = true
+	return t
+}
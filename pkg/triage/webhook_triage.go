@@ -0,0 +1,164 @@
+package triage
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"strings"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	kubeSystemNamespace = "kube-system"
+	admissionGroup      = "admissionregistration.k8s.io"
+)
+
+// webhookConfiguration holds the webhooks of a validating or mutating webhook configuration,
+// the v1beta1 webhook type covers every field doctor reads from both v1 and v1beta1
+type webhookConfiguration struct {
+	v1.ObjectMeta `json:"metadata,omitempty"`
+	Webhooks      []admissionv1beta1.Webhook `json:"webhooks,omitempty"`
+}
+
+// listWebhookConfigurations gets a dynamic client and a webhook configuration resource then lists it from
+// admissionregistration.k8s.io/v1, falling back to v1beta1 on servers that predate it
+func listWebhookConfigurations(dynamicCli dynamic.Interface, resource string) ([]webhookConfiguration, error) {
+	configurations := make([]webhookConfiguration, 0)
+	list, err := dynamicCli.Resource(schema.GroupVersionResource{Group: admissionGroup, Version: "v1", Resource: resource}).
+		List(v1.ListOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		list, err = dynamicCli.Resource(schema.GroupVersionResource{Group: admissionGroup, Version: "v1beta1", Resource: resource}).
+			List(v1.ListOptions{})
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return configurations, nil
+	}
+	for _, i := range list.Items {
+		configuration := webhookConfiguration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &configuration); err != nil {
+			return nil, err
+		}
+		configurations = append(configurations, configuration)
+	}
+	return configurations, nil
+}
+
+// TriageWebhooks gets a kubernetes.Clientset and a dynamic client and checks validating and mutating admission webhooks
+// whose backing Service is missing or has no ready endpoints (fatal for every matching request when
+// failurePolicy is Fail), whose caBundle holds expired certificates, and that intercept kube-system
+// because their namespaceSelector does not exclude it
+func TriageWebhooks(kubeCli *kubernetes.Clientset, dynamicCli dynamic.Interface) (*Triage, error) {
+	listOfTriages := make([]string, 0)
+	objects := make(map[string]ObjectRef)
+	validating, err := listWebhookConfigurations(dynamicCli, "validatingwebhookconfigurations")
+	if err != nil {
+		return nil, err
+	}
+	mutating, err := listWebhookConfigurations(dynamicCli, "mutatingwebhookconfigurations")
+	if err != nil {
+		return nil, err
+	}
+
+	kubeSystemLabels := labels.Set{}
+	kubeSystem, err := kubeCli.CoreV1().Namespaces().Get(kubeSystemNamespace, v1.GetOptions{})
+	if err == nil {
+		kubeSystemLabels = labels.Set(kubeSystem.GetLabels())
+	}
+
+	configurations := make(map[ObjectRef][]admissionv1beta1.Webhook)
+	for _, i := range validating {
+		configurations[ObjectRef{"ValidatingWebhookConfiguration", "", i.GetName()}] = i.Webhooks
+	}
+	for _, i := range mutating {
+		configurations[ObjectRef{"MutatingWebhookConfiguration", "", i.GetName()}] = i.Webhooks
+	}
+
+	currentTime := time.Now()
+	for configuration, webhooks := range configurations {
+		for _, w := range webhooks {
+			issues := make([]string, 0)
+
+			if svc := w.ClientConfig.Service; svc != nil {
+				ready, err := serviceHasReadyEndpoints(kubeCli, svc.Namespace, svc.Name)
+				if err != nil {
+					return nil, err
+				}
+				if !ready {
+					issue := "service " + svc.Namespace + "/" + svc.Name + " is missing or has no ready endpoints"
+					if w.FailurePolicy != nil && *w.FailurePolicy == admissionv1beta1.Fail {
+						issue += " and failurePolicy is Fail, matching requests are rejected"
+					}
+					issues = append(issues, issue)
+				}
+			}
+
+			for _, expired := range expiredCertificates(w.ClientConfig.CABundle, currentTime) {
+				issues = append(issues, "caBundle certificate "+expired)
+			}
+
+			selector, err := v1.LabelSelectorAsSelector(w.NamespaceSelector)
+			if err == nil && selector.Matches(kubeSystemLabels) {
+				issues = append(issues, "namespaceSelector does not exclude "+kubeSystemNamespace)
+			}
+
+			if len(issues) > 0 {
+				anomaly := configuration.Kind + "/" + configuration.Name + " " + w.Name + ": " + strings.Join(issues, ", ")
+				listOfTriages = append(listOfTriages, anomaly)
+				objects[anomaly] = configuration
+			}
+		}
+	}
+	sort.Strings(listOfTriages)
+	return NewTriage("AdmissionWebhooks", "Found unhealthy or risky admission webhooks!", listOfTriages).WithObjects(objects), nil
+}
+
+// serviceHasReadyEndpoints reports whether a Service exists and has at least one ready endpoint address
+func serviceHasReadyEndpoints(kubeCli *kubernetes.Clientset, namespace, name string) (bool, error) {
+	endpoints, err := kubeCli.CoreV1().Endpoints(namespace).Get(name, v1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, s := range endpoints.Subsets {
+		if len(s.Addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// expiredCertificates parses a PEM bundle and describes the certificates in it that are expired at the given time
+func expiredCertificates(bundle []byte, now time.Time) []string {
+	expired := make([]string, 0)
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			return expired
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if now.After(cert.NotAfter) {
+			expired = append(expired, cert.Subject.CommonName+" expired at "+cert.NotAfter.Format(time.RFC3339))
+		}
+	}
+}
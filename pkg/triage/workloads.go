@@ -0,0 +1,95 @@
+package triage
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// workload is a pod template owning controller, findings about pods are reported against it
+// rather than against every single replica
+type workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Selector  *v1.LabelSelector
+	Template  corev1.PodTemplateSpec
+}
+
+func (w workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+func (w workload) ref() ObjectRef {
+	return ObjectRef{w.Kind, w.Namespace, w.Name}
+}
+
+// listWorkloads gets a kubernetes.Clientset and a specific namespace string
+// then lists the Deployments, StatefulSets and DaemonSets in it
+func listWorkloads(kubeCli *kubernetes.Clientset, namespace string) ([]workload, error) {
+	workloads := make([]workload, 0)
+
+	deployments, err := kubeCli.AppsV1().Deployments(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range deployments.Items {
+		workloads = append(workloads, workload{"Deployment", i.GetNamespace(), i.GetName(), i.Spec.Selector, i.Spec.Template})
+	}
+
+	statefulSets, err := kubeCli.AppsV1().StatefulSets(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range statefulSets.Items {
+		workloads = append(workloads, workload{"StatefulSet", i.GetNamespace(), i.GetName(), i.Spec.Selector, i.Spec.Template})
+	}
+
+	daemonSets, err := kubeCli.AppsV1().DaemonSets(namespace).List(v1.ListOptions{})
+	if err != nil {
+		if err.Error() != KUBE_RESOURCE_NOT_FOUND {
+			return nil, err
+		}
+	}
+	for _, i := range daemonSets.Items {
+		workloads = append(workloads, workload{"DaemonSet", i.GetNamespace(), i.GetName(), i.Spec.Selector, i.Spec.Template})
+	}
+
+	return workloads, nil
+}
+
+// getWorkloadTemplate gets the pod template of a scalable workload by kind and name,
+// kinds doctor does not know about return a nil template and no error
+func getWorkloadTemplate(kubeCli *kubernetes.Clientset, namespace, kind, name string) (*corev1.PodTemplateSpec, error) {
+	switch kind {
+	case "Deployment":
+		d, err := kubeCli.AppsV1().Deployments(namespace).Get(name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &d.Spec.Template, nil
+	case "StatefulSet":
+		s, err := kubeCli.AppsV1().StatefulSets(namespace).Get(name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &s.Spec.Template, nil
+	case "ReplicaSet":
+		r, err := kubeCli.AppsV1().ReplicaSets(namespace).Get(name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &r.Spec.Template, nil
+	case "ReplicationController":
+		r, err := kubeCli.CoreV1().ReplicationControllers(namespace).Get(name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return r.Spec.Template, nil
+	}
+	return nil, nil
+}